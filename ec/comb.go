@@ -15,3 +15,120 @@ func NextCombination(s []int, n, k int) {
 		break
 	}
 }
+
+// NextSubset returns the next larger number with the same number of set bits as x (Gosper's hack).
+// Starting from the k lowest bits, it walks every combination of k bits in increasing order.
+func NextSubset(x uint64) uint64 {
+	lowest := x & -x
+	ripple := x + lowest
+	return ripple | ((x^ripple)>>2)/lowest
+}
+
+// Deposit scatters the low bits of x to the positions of the set bits of mask, like the PDEP instruction.
+// Ex. Deposit(0b101, 0b11010) is 0b10010.
+func Deposit(x, mask uint64) uint64 {
+	deposited := uint64(0)
+	for ; x != 0 && mask != 0; x >>= 1 {
+		lowest := mask & -mask
+		if x&1 != 0 {
+			deposited |= lowest
+		}
+		mask &^= lowest
+	}
+	return deposited
+}
+
+// Depositor is Deposit for a fixed mask, with a table of the deposited bits of every byte of x.
+type Depositor [][256]uint64
+
+// NewDepositor returns a Depositor for a mask.
+func NewDepositor(mask uint64) Depositor {
+	d := make(Depositor, 0, 8)
+	for ; mask != 0; mask = Deposit(^uint64(0xff), mask) {
+		var table [256]uint64
+		for b := range table {
+			table[b] = Deposit(uint64(b), mask)
+		}
+		d = append(d, table)
+	}
+	return d
+}
+
+// Deposit scatters the low bits of x to the positions of the set bits of the Depositor's mask.
+func (d Depositor) Deposit(x uint64) uint64 {
+	deposited := uint64(0)
+	for i := range d {
+		deposited |= d[i][byte(x>>(8*i))]
+	}
+	return deposited
+}
+
+// RevolvingDoor walks every combination of k out of n elements, where each combination differs from the
+// previous one by one element leaving and one element entering (Knuth's Algorithm R, a Gray code).
+type RevolvingDoor struct {
+	k int
+	c []int // c[1] to c[k] is the combination, c[k+1] and c[k+2] are sentinels
+}
+
+// NewRevolvingDoor returns a RevolvingDoor at the first combination [0, 1, 2 ... k-1].
+func NewRevolvingDoor(n, k int) *RevolvingDoor {
+	c := make([]int, k+3)
+	for j := 1; j <= k; j++ {
+		c[j] = j - 1
+	}
+	c[k+1] = n
+	c[k+2] = n + 1
+	return &RevolvingDoor{k: k, c: c}
+}
+
+// Combination returns the current combination in increasing order, the slice must not be modified.
+func (r *RevolvingDoor) Combination() []int {
+	return r.c[1 : r.k+1]
+}
+
+// Next moves to the next combination and returns the element that left and the element that entered.
+// It returns false if there are no more combinations.
+func (r *RevolvingDoor) Next() (out, in int, ok bool) {
+	c := r.c
+	if r.k == 0 {
+		return 0, 0, false
+	}
+
+	// easy cases, only the smallest element changes
+	decrease := true
+	if r.k%2 == 1 {
+		if c[1]+1 < c[2] {
+			c[1]++
+			return c[1] - 1, c[1], true
+		}
+	} else {
+		if c[1] > 0 {
+			c[1]--
+			return c[1] + 1, c[1], true
+		}
+		decrease = false
+	}
+
+	for j := 2; j <= r.k; j++ {
+		// try to decrease c[j], at this point c[j] == c[j-1]+1
+		if decrease {
+			if c[j] >= j {
+				out, in = c[j], j-2
+				c[j] = c[j-1]
+				c[j-1] = j - 2
+				return out, in, true
+			}
+			j++
+		}
+		decrease = true
+
+		// try to increase c[j], at this point c[j-1] == j-2
+		if c[j]+1 < c[j+1] {
+			out, in = c[j-1], c[j]+1
+			c[j-1] = c[j]
+			c[j]++
+			return out, in, true
+		}
+	}
+	return 0, 0, false
+}
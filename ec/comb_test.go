@@ -2,6 +2,7 @@ package ec
 
 import (
 	"fmt"
+	"math/bits"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -83,3 +84,68 @@ func TestCombinManual(t *testing.T) {
 	assert.Equal(t, 12650, idx)
 	assert.ElementsMatch(t, []int{21, 22, 23, 24}, s)
 }
+
+func TestNextSubset(t *testing.T) {
+	n := 25
+	k := 4
+	total := combin.Binomial(n, k)
+	x := uint64(1)<<k - 1
+	for idx := 1; idx < total; idx++ {
+		next := NextSubset(x)
+		assert.Less(t, x, next)
+		assert.Equal(t, k, bits.OnesCount64(next))
+		x = next
+	}
+	assert.Equal(t, uint64(0b1111)<<(n-k), x)
+}
+
+func TestDeposit(t *testing.T) {
+	assert.Equal(t, uint64(0b10010), Deposit(0b101, 0b11010))
+	assert.Equal(t, uint64(0b11010), Deposit(0b111, 0b11010))
+	assert.Equal(t, uint64(0), Deposit(0b1000, 0b11010))
+	assert.Equal(t, uint64(1)<<63, Deposit(1, 1<<63))
+}
+
+func TestDepositor(t *testing.T) {
+	mask := uint64(0xf0f0_0ff0_1234_8001)
+	d := NewDepositor(mask)
+	for _, x := range []uint64{0, 1, 0b1011, 0xffff, 0x1_2345_6789, ^uint64(0)} {
+		assert.Equal(t, Deposit(x, mask), d.Deposit(x))
+	}
+}
+
+func TestRevolvingDoor(t *testing.T) {
+	for n := 0; n <= 8; n++ {
+		for k := 0; k <= n; k++ {
+			seen := make(map[string]interface{})
+			r := NewRevolvingDoor(n, k)
+			prev := append([]int(nil), r.Combination()...)
+			seen[fmt.Sprint(prev)] = nil
+			for {
+				out, in, ok := r.Next()
+				if !ok {
+					break
+				}
+				comb := r.Combination()
+				expected := make(map[int]interface{})
+				for _, v := range prev {
+					expected[v] = nil
+				}
+				assert.Contains(t, expected, out)
+				assert.NotContains(t, expected, in)
+				delete(expected, out)
+				expected[in] = nil
+				for i, v := range comb {
+					assert.Contains(t, expected, v)
+					if i > 0 {
+						assert.Less(t, comb[i-1], v)
+					}
+				}
+				assert.True(t, len(comb) == 0 || comb[len(comb)-1] < n)
+				seen[fmt.Sprint(comb)] = nil
+				prev = append(prev[:0], comb...)
+			}
+			assert.Equal(t, combin.Binomial(n, k), len(seen), "n %d k %d", n, k)
+		}
+	}
+}
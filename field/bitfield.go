@@ -2,6 +2,7 @@ package field
 
 import (
 	"fmt"
+	"math/bits"
 )
 
 type (
@@ -15,7 +16,11 @@ type (
 		matchSpace    *uint64
 		matchList     []uint64
 		spaceList     []uint64
+		spaceMask     uint64   // all initial spaces
 		squares       []uint64 // list of combinations of matches that can form a square
+		matchSquares  [][]int  // the squares that each match of the match list is part of
+		spaceSquares  [][]int  // the squares that each space of the space list is part of
+		counts        squareCounts
 	}
 )
 
@@ -105,20 +110,25 @@ func NewBitField(width, height int, initialMatches []*MatchPosition) *BitField {
 	}
 
 	// add matches and spaces to lists, used for trying combinations of removals and placements
+	lines := lineSquares(width, height)
 	matchList := make([]uint64, matches)
 	spaceList := make([]uint64, spaces)
+	matchSquares := make([][]int, matches)
+	spaceSquares := make([][]int, spaces)
 	for i, mi, si := 0, 0, 0; i < area; i++ {
 		m := uint64(1 << i)
 		if matchSpace&m > 0 {
 			matchList[mi] = m
+			matchSquares[mi] = lines[i]
 			mi++
 		} else {
 			spaceList[si] = m
+			spaceSquares[si] = lines[i]
 			si++
 		}
 	}
 
-	return &BitField{
+	f := &BitField{
 		matches:       matches,
 		spaces:        spaces,
 		width:         width,
@@ -126,10 +136,16 @@ func NewBitField(width, height int, initialMatches []*MatchPosition) *BitField {
 		linearMapping: linearMapping,
 		matchList:     matchList,
 		spaceList:     spaceList,
+		spaceMask:     ^matchSpace & (1<<area - 1),
 		matchSpace:    &matchSpace,
 
-		squares: squares,
+		squares:      squares,
+		matchSquares: matchSquares,
+		spaceSquares: spaceSquares,
+		counts:       newSquareCounts(width, height),
 	}
+	f.SetLayout(matchSpace)
+	return f
 }
 
 func (f *BitField) to1D(x, y int, s Side) int {
@@ -174,48 +190,142 @@ func (f *BitField) GetSpacesCount() int {
 	return f.spaces
 }
 
+// GetEdges returns the Edges of the initial matches or spaces, in the order of the match or space list.
+func (f *BitField) GetEdges(state State) []Edge {
+	list := f.spaceList
+	if state == Match {
+		list = f.matchList
+	}
+
+	lineEdges := Edges(f.width, f.height)
+	edges := make([]Edge, len(list))
+	for i, b := range list {
+		edges[i] = lineEdges[bits.TrailingZeros64(b)]
+	}
+	return edges
+}
+
 // ChangeToState will change all matches or spaces from a list of indices to the desired state.
 // Ex. ChangeToState([]int{1, 2, 3}, Match, Space)
 // finds matches 1, 2, 3 in the match list, and changes them to spaces.
 func (f *BitField) ChangeToState(l []int, fromState State, toState State) {
-	var list []uint64
+	for _, v := range l {
+		f.ChangeOne(v, fromState, toState)
+	}
+}
+
+// ChangeOne is ChangeToState for a single match or space,
+// it only updates the squares that the match or space is part of.
+func (f *BitField) ChangeOne(i int, fromState State, toState State) {
+	var b uint64
+	var squares []int
 	if fromState == Match {
-		list = f.matchList
+		b, squares = f.matchList[i], f.matchSquares[i]
 	} else {
-		list = f.spaceList
+		b, squares = f.spaceList[i], f.spaceSquares[i]
+	}
+	if (*f.matchSpace&b != 0) == bool(toState) {
+		return
 	}
 
-	var changeBit func(b uint64)
 	if toState == Match {
 		// set bit
-		changeBit = func(b uint64) {
-			*f.matchSpace |= b
-		}
+		*f.matchSpace |= b
 	} else {
 		// clear bit
-		changeBit = func(b uint64) {
-			*f.matchSpace &^= b
-		}
+		*f.matchSpace &^= b
 	}
+	f.counts.change(squares, toState)
+}
 
-	for _, v := range l {
-		changeBit(list[v])
-	}
+// GetSquaresCount returns the number of complete squares, which is kept up to date as matches change.
+func (f *BitField) GetSquaresCount() int {
+	return f.counts.complete
+}
+
+// GetCoveredCount returns the number of matches that are part of a complete square,
+// which is kept up to date as matches change.
+func (f *BitField) GetCoveredCount() int {
+	return f.counts.covered
 }
 
 // CheckSquares returns true if the number of squares is equal to the amount required
 // and all matches were visited.
 func (f *BitField) CheckSquares(requiredShapes int) bool {
+	count, covered := f.CountSquares()
+	return count == requiredShapes && covered
+}
+
+// CountSquares returns the number of squares and whether all matches are part of a square.
+// Both are kept up to date as matches change, so it does not look at the squares.
+func (f *BitField) CountSquares() (int, bool) {
+	return f.counts.complete, f.counts.covered == bits.OnesCount64(*f.matchSpace)
+}
+
+// CompletableSquares returns the squares that could be completed by placing at most placeable matches
+// on the initial spaces, as bit masks. No other square can be present after placing those matches.
+func (f *BitField) CompletableSquares(placeable int) []uint64 {
+	return f.CompletableSquaresOf(*f.matchSpace, placeable)
+}
+
+// CompletableSquaresOf is CompletableSquares for a layout of matches given as a bit mask, instead of the current one.
+func (f *BitField) CompletableSquaresOf(layout uint64, placeable int) []uint64 {
+	squares := make([]uint64, 0)
+	for _, s := range f.squares {
+		missing := s &^ layout
+		if missing&^f.spaceMask == 0 && bits.OnesCount64(missing) <= placeable {
+			squares = append(squares, s)
+		}
+	}
+	return squares
+}
+
+// GetMask returns the initial matches or spaces as a bit mask.
+func (f *BitField) GetMask(state State) uint64 {
+	if state == Space {
+		return f.spaceMask
+	}
+	mask := uint64(0)
+	for _, m := range f.matchList {
+		mask |= m
+	}
+	return mask
+}
+
+// GetLayout returns the current matches as a bit mask.
+func (f *BitField) GetLayout() uint64 {
+	return *f.matchSpace
+}
+
+// SetLayout changes the current matches to the ones in a bit mask.
+func (f *BitField) SetLayout(layout uint64) {
+	*f.matchSpace = layout
+	f.counts.reset(func(i int) bool {
+		return layout&(1<<i) != 0
+	})
+}
+
+// Bound returns the number of squares that could be completed by placing at most placeable matches
+// on the initial spaces, and whether every match is part of one of those squares.
+// Squares that need more placements than that together are still counted,
+// so the number is an upper bound on the number of squares that can be reached.
+func (f *BitField) Bound(placeable int) (int, bool) {
+	return f.BoundOf(*f.matchSpace, placeable)
+}
+
+// BoundOf is Bound for a layout of matches given as a bit mask, instead of the current one.
+func (f *BitField) BoundOf(layout uint64, placeable int) (int, bool) {
 	count := 0
-	visitedMatches := uint64(0)
+	coverable := uint64(0)
 	for _, s := range f.squares {
-		if *f.matchSpace&s == s {
+		missing := s &^ layout
+		if missing&^f.spaceMask == 0 && bits.OnesCount64(missing) <= placeable {
 			count++
-			visitedMatches |= s
+			coverable |= s
 		}
 	}
 
-	return count == requiredShapes && *f.matchSpace^visitedMatches == 0
+	return count, layout&^coverable == 0
 }
 
 // Copy returns a copy of this BitField.
@@ -230,6 +340,10 @@ func (f *BitField) Copy(bool) Copyable {
 		matchSpace:    &newMatchSpace,
 		matchList:     f.matchList,
 		spaceList:     f.spaceList,
+		spaceMask:     f.spaceMask,
 		squares:       f.squares,
+		matchSquares:  f.matchSquares,
+		spaceSquares:  f.spaceSquares,
+		counts:        f.counts.copy(),
 	}
 }
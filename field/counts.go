@@ -0,0 +1,104 @@
+package field
+
+type (
+	// squareCounts keeps the number of missing matches of every square, the number of complete squares
+	// and the number of matches that are part of a complete square,
+	// so that changing a match only updates the squares that it is part of.
+	squareCounts struct {
+		squares  [][]int // line indices of every square, shared between copies
+		missing  []int   // the number of missing matches of every square
+		coverage []int   // the number of complete squares that each line index is part of
+		complete int
+		covered  int
+	}
+)
+
+func newSquareCounts(width, height int) squareCounts {
+	squares := Squares(width, height)
+	return squareCounts{
+		squares:  squares,
+		missing:  make([]int, len(squares)),
+		coverage: make([]int, 2*width*height+width+height),
+	}
+}
+
+// lineSquares returns the indices of the squares that each line index is part of,
+// on a field with the given width and height.
+func lineSquares(width, height int) [][]int {
+	lines := make([][]int, 2*width*height+width+height)
+	for j, square := range Squares(width, height) {
+		for _, i := range square {
+			lines[i] = append(lines[i], j)
+		}
+	}
+	return lines
+}
+
+// reset counts everything again for a layout, where isMatch tells if there is a match at a line index.
+func (c *squareCounts) reset(isMatch func(i int) bool) {
+	for i := range c.coverage {
+		c.coverage[i] = 0
+	}
+	c.complete = 0
+	c.covered = 0
+	for j, square := range c.squares {
+		c.missing[j] = 0
+		for _, i := range square {
+			if !isMatch(i) {
+				c.missing[j]++
+			}
+		}
+		if c.missing[j] == 0 {
+			c.add(j)
+		}
+	}
+}
+
+// change updates the squares that a match is part of, after it changed to toState.
+func (c *squareCounts) change(squares []int, toState State) {
+	for _, j := range squares {
+		if toState == Match {
+			c.missing[j]--
+			if c.missing[j] == 0 {
+				c.add(j)
+			}
+		} else {
+			if c.missing[j] == 0 {
+				c.drop(j)
+			}
+			c.missing[j]++
+		}
+	}
+}
+
+// add counts square j as complete.
+func (c *squareCounts) add(j int) {
+	c.complete++
+	for _, i := range c.squares[j] {
+		c.coverage[i]++
+		if c.coverage[i] == 1 {
+			c.covered++
+		}
+	}
+}
+
+// drop counts square j as no longer complete.
+func (c *squareCounts) drop(j int) {
+	c.complete--
+	for _, i := range c.squares[j] {
+		c.coverage[i]--
+		if c.coverage[i] == 0 {
+			c.covered--
+		}
+	}
+}
+
+func (c *squareCounts) copy() squareCounts {
+	return squareCounts{
+		squares:  c.squares,
+		missing:  append([]int(nil), c.missing...),
+		coverage: append([]int(nil), c.coverage...),
+		complete: c.complete,
+		covered:  c.covered,
+	}
+}
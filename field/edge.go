@@ -0,0 +1,149 @@
+package field
+
+type (
+	// Edge is the position of a match space on the lines of the grid.
+	// A horizontal Edge runs right from the grid point (X, Y), a Vertical Edge runs down from it.
+	// Unlike MatchPosition, every match space has exactly one Edge.
+	Edge struct {
+		X        int
+		Y        int
+		Vertical bool
+	}
+)
+
+// Edge returns the Edge of the match space on the given Side of the Cell.
+func (p *MatchPosition) Edge() Edge {
+	switch p.S {
+	case Top:
+		return Edge{X: p.X, Y: p.Y}
+	case Bot:
+		return Edge{X: p.X, Y: p.Y + 1}
+	case Lft:
+		return Edge{X: p.X, Y: p.Y, Vertical: true}
+	case Rgt:
+		return Edge{X: p.X + 1, Y: p.Y, Vertical: true}
+	default:
+		panic("unknown side")
+	}
+}
+
+// Position returns the Cell and Side of an Edge on a field with the given width and height.
+// Edges on the bottom and right borders of the field are given as the Bot and Rgt of the last Cell.
+func (e Edge) Position(width, height int) *MatchPosition {
+	switch {
+	case e.Vertical && e.X == width:
+		return &MatchPosition{X: e.X - 1, Y: e.Y, S: Rgt}
+	case e.Vertical:
+		return &MatchPosition{X: e.X, Y: e.Y, S: Lft}
+	case e.Y == height:
+		return &MatchPosition{X: e.X, Y: e.Y - 1, S: Bot}
+	default:
+		return &MatchPosition{X: e.X, Y: e.Y, S: Top}
+	}
+}
+
+// Index returns the line index of an Edge on a field with the given width and height,
+// or -1 if the Edge is not on the field.
+// The line index is the order in which both field types lay out their match spaces.
+func (e Edge) Index(width, height int) int {
+	if e.Vertical {
+		switch {
+		case e.X < 0 || e.X > width || e.Y < 0 || e.Y >= height:
+			return -1
+		case e.X == width:
+			return 2*width*height + width + e.Y
+		default:
+			return 2*(e.X*height+e.Y) + 1
+		}
+	}
+	switch {
+	case e.X < 0 || e.X >= width || e.Y < 0 || e.Y > height:
+		return -1
+	case e.Y == height:
+		return 2*width*height + e.X
+	default:
+		return 2 * (e.X*height + e.Y)
+	}
+}
+
+// Edges returns every Edge of a field with the given width and height, ordered by line index.
+func Edges(width, height int) []Edge {
+	edges := make([]Edge, 2*width*height+width+height)
+	for i := 0; i < width; i++ {
+		for j := 0; j < height; j++ {
+			edges[2*(i*height+j)] = Edge{X: i, Y: j}
+			edges[2*(i*height+j)+1] = Edge{X: i, Y: j, Vertical: true}
+		}
+	}
+	for i := 0; i < width; i++ {
+		edges[2*width*height+i] = Edge{X: i, Y: height}
+	}
+	for j := 0; j < height; j++ {
+		edges[2*width*height+width+j] = Edge{X: width, Y: j, Vertical: true}
+	}
+	return edges
+}
+
+// Symmetries returns the reflections and rotations that map a field with the given width and height onto itself.
+// Each one is a permutation of line indices, where the Edge at line index i is mapped to line index symmetry[i].
+// The first one is the identity, rotations by 90 degrees are only included for square fields.
+func Symmetries(width, height int) [][]int {
+	transforms := []func(x, y int) (int, int){
+		func(x, y int) (int, int) { return x, y },
+		func(x, y int) (int, int) { return width - x, y },
+		func(x, y int) (int, int) { return x, height - y },
+		func(x, y int) (int, int) { return width - x, height - y },
+	}
+	if width == height {
+		n := width
+		transforms = append(transforms,
+			func(x, y int) (int, int) { return y, x },
+			func(x, y int) (int, int) { return n - y, x },
+			func(x, y int) (int, int) { return y, n - x },
+			func(x, y int) (int, int) { return n - y, n - x },
+		)
+	}
+
+	edges := Edges(width, height)
+	symmetries := make([][]int, len(transforms))
+	for t, transform := range transforms {
+		symmetry := make([]int, len(edges))
+		for i, e := range edges {
+			x1, y1 := transform(e.X, e.Y)
+			x2, y2 := transform(e.X+1, e.Y)
+			if e.Vertical {
+				x2, y2 = transform(e.X, e.Y+1)
+			}
+			vertical := x1 == x2
+			if x1 > x2 || y1 > y2 {
+				x1, y1 = x2, y2
+			}
+			symmetry[i] = Edge{X: x1, Y: y1, Vertical: vertical}.Index(width, height)
+		}
+		symmetries[t] = symmetry
+	}
+	return symmetries
+}
+
+// Squares returns the line indices of the Edges of every square on a field with the given width and height,
+// in the same order as the squares of both field types.
+func Squares(width, height int) [][]int {
+	squares := make([][]int, 0)
+	for i := 0; i < width; i++ {
+		for j := 0; j < height; j++ {
+			for size := 1; size <= width-i && size <= height-j; size++ {
+				square := make([]int, 0, 4*size)
+				for k := 0; k < size; k++ {
+					square = append(square,
+						Edge{X: i + k, Y: j}.Index(width, height),
+						Edge{X: i + k, Y: j + size}.Index(width, height),
+						Edge{X: i, Y: j + k, Vertical: true}.Index(width, height),
+						Edge{X: i + size, Y: j + k, Vertical: true}.Index(width, height),
+					)
+				}
+				squares = append(squares, square)
+			}
+		}
+	}
+	return squares
+}
@@ -21,7 +21,14 @@ type (
 		matchList []*State
 		spaceList []*State
 
-		visitedMatches  map[*State]interface{}
+		matchEdges   []Edge  // edges of the match list
+		spaceEdges   []Edge  // edges of the space list
+		matchSquares [][]int // the squares that each match of the match list is part of
+		spaceSquares [][]int // the squares that each space of the space list is part of
+		spaceLines   []bool  // whether each line index is on the space list, shared between copies
+		visited      []bool  // the line indices of the squares that Bound can complete, reused between calls
+		counts       squareCounts
+
 		squares         [][]*State // list of combinations of matches that may form a square
 		requiredVisited int        // the required number of matches visited
 	}
@@ -62,19 +69,36 @@ func NewField(width, height, removableMatches int, initialMatches []*MatchPositi
 	}
 
 	// add matches and spaces to lists, used for trying combinations of removals and placements
+	edges := Edges(width, height)
+	lines := lineSquares(width, height)
 	matchList := make([]*State, matches)
 	spaceList := make([]*State, spaces)
+	matchEdges := make([]Edge, matches)
+	spaceEdges := make([]Edge, spaces)
+	matchSquares := make([][]int, matches)
+	spaceSquares := make([][]int, spaces)
+	spaceLines := make([]bool, area)
 	for i, mi, si := 0, 0, 0; i < area; i++ {
 		m := lineSpace[i]
 		if *m == Match {
 			matchList[mi] = m
+			matchEdges[mi] = edges[i]
+			matchSquares[mi] = lines[i]
 			mi++
 		} else {
 			spaceList[si] = m
+			spaceEdges[si] = edges[i]
+			spaceSquares[si] = lines[i]
+			spaceLines[i] = true
 			si++
 		}
 	}
 
+	counts := newSquareCounts(width, height)
+	counts.reset(func(i int) bool {
+		return *lineSpace[i] == Match
+	})
+
 	requiredVisited := matches - removableMatches
 
 	return &Field{
@@ -87,7 +111,13 @@ func NewField(width, height, removableMatches int, initialMatches []*MatchPositi
 		matchList: matchList,
 		spaceList: spaceList,
 
-		visitedMatches:  make(map[*State]interface{}, matches),
+		matchEdges:   matchEdges,
+		spaceEdges:   spaceEdges,
+		matchSquares: matchSquares,
+		spaceSquares: spaceSquares,
+		spaceLines:   spaceLines,
+		counts:       counts,
+
 		squares:         squares,
 		requiredVisited: requiredVisited,
 	}
@@ -131,60 +161,112 @@ func (f *Field) GetSpacesCount() int {
 	return f.spaces
 }
 
+// GetEdges returns the Edges of the initial matches or spaces, in the order of the match or space list.
+func (f *Field) GetEdges(state State) []Edge {
+	if state == Match {
+		return f.matchEdges
+	}
+	return f.spaceEdges
+}
+
 // ChangeToState will change all matches or spaces from a list of indices to the desired state.
 // Ex. ChangeToState([]int{1, 2, 3}, Match, Space)
 // finds matches 1, 2, 3 in the match list, and changes them to spaces.
 func (f *Field) ChangeToState(l []int, fromState State, toState State) {
-	var mapping []*State
+	for _, v := range l {
+		f.ChangeOne(v, fromState, toState)
+	}
+}
+
+// ChangeOne is ChangeToState for a single match or space,
+// it only updates the squares that the match or space is part of.
+func (f *Field) ChangeOne(i int, fromState State, toState State) {
+	var m *State
+	var squares []int
 	if fromState == Match {
-		mapping = f.matchList
+		m, squares = f.matchList[i], f.matchSquares[i]
 	} else {
-		mapping = f.spaceList
+		m, squares = f.spaceList[i], f.spaceSquares[i]
 	}
-
-	for _, v := range l {
-		*mapping[v] = toState
+	if *m == toState {
+		return
 	}
+
+	*m = toState
+	f.counts.change(squares, toState)
+}
+
+// GetSquaresCount returns the number of complete squares, which is kept up to date as matches change.
+func (f *Field) GetSquaresCount() int {
+	return f.counts.complete
+}
+
+// GetCoveredCount returns the number of matches that are part of a complete square,
+// which is kept up to date as matches change.
+func (f *Field) GetCoveredCount() int {
+	return f.counts.covered
 }
 
 // CheckSquares returns true if the number of squares is equal to the amount required
 // and all matches were visited.
 func (f *Field) CheckSquares(requiredShapes int) bool {
-	squareCount := 0
-	addUnique := func(newMatches ...*State) {
-		for _, nm := range newMatches {
-			_, visited := f.visitedMatches[nm]
-			if !visited {
-				f.visitedMatches[nm] = nil
-			}
-		}
+	count, covered := f.CountSquares()
+	return count == requiredShapes && covered
+}
+
+// CountSquares returns the number of squares and whether all matches are part of a square.
+// Both are kept up to date as matches change, so it does not look at the squares.
+func (f *Field) CountSquares() (int, bool) {
+	return f.counts.complete, f.counts.covered == f.requiredVisited
+}
+
+// Bound returns the number of squares that could be completed by placing at most placeable matches
+// on the spaces of the space list, and whether every match is part of one of those squares.
+// Squares that need more placements than that together are still counted,
+// so the number is an upper bound on the number of squares that can be reached.
+func (f *Field) Bound(placeable int) (int, bool) {
+	if f.visited == nil {
+		f.visited = make([]bool, len(f.lineSpace))
 	}
-	for _, square := range f.squares {
-		present := true // all matches in the square are assumed present
-		for _, match := range square {
-			if *match == Space {
-				present = false
+	for i := range f.visited {
+		f.visited[i] = false
+	}
+
+	count := 0
+	for _, square := range f.counts.squares {
+		missing := 0
+		for _, i := range square {
+			if *f.lineSpace[i] == Match {
+				continue
+			}
+			if !f.spaceLines[i] {
+				missing = placeable + 1
 				break
 			}
+			missing++
 		}
-		if present {
-			squareCount++
-			addUnique(square...)
+		if missing <= placeable {
+			count++
+			for _, i := range square {
+				f.visited[i] = true
+			}
 		}
 	}
-	visited := len(f.visitedMatches)
 
-	// clear visited set (compiler optimized)
-	for k := range f.visitedMatches {
-		delete(f.visitedMatches, k)
+	coverable := true
+	for i, m := range f.lineSpace {
+		if *m == Match && !f.visited[i] {
+			coverable = false
+			break
+		}
 	}
 
-	return squareCount == requiredShapes && visited == f.requiredVisited
+	return count, coverable
 }
 
 // Copy returns a copy of this Field.
 // If displayOnly is set, then this copy can only be used to display a state, and does not require a spaceList.
-// If displayOnly is not set, the Field's spaceList will also be copied and
+// If displayOnly is not set, the Field's matchList and spaceList will also be copied and
 // this copy can be used for generating more possible field states.
 // todo: don't copy squares if it's for display only
 func (f *Field) Copy(displayOnly bool) Copyable {
@@ -207,29 +289,39 @@ func (f *Field) Copy(displayOnly bool) Copyable {
 		height:          h,
 		gridSpace:       gridSpace,
 		lineSpace:       lineSpace,
-		matchList:       nil, // not needed
+		matchList:       nil, // may be included
 		spaceList:       nil, // may be included
-		visitedMatches:  make(map[*State]interface{}, f.matches),
+		matchEdges:      f.matchEdges,
+		spaceEdges:      f.spaceEdges,
+		matchSquares:    f.matchSquares,
+		spaceSquares:    f.spaceSquares,
+		spaceLines:      f.spaceLines,
+		counts:          f.counts.copy(),
 		squares:         squares,
 		requiredVisited: f.requiredVisited,
 	}
 
 	if !displayOnly {
-		spaceList := make([]*State, len(f.spaceList))
-		spaceListIndex := 0
-		for i, m := range f.lineSpace {
-			if m == f.spaceList[spaceListIndex] {
-				spaceList[spaceListIndex] = lineSpace[i]
-				spaceListIndex++
-			}
-		}
-
-		newField.spaceList = spaceList
+		newField.matchList = copyList(f.matchList, f.lineSpace, lineSpace)
+		newField.spaceList = copyList(f.spaceList, f.lineSpace, lineSpace)
 	}
 
 	return newField
 }
 
+// copyList returns a list that points into the line space to, in the same places as list points into from.
+func copyList(list, from, to []*State) []*State {
+	newList := make([]*State, len(list))
+	listIndex := 0
+	for i, m := range from {
+		if listIndex < len(list) && m == list[listIndex] {
+			newList[listIndex] = to[i]
+			listIndex++
+		}
+	}
+	return newList
+}
+
 func createLinkedSpaces(width, height int, gridSpace [][]*Cell, lineSpace []*State, squares *[][]*State) {
 	lineSpaceIndex := 0
 
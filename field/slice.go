@@ -0,0 +1,84 @@
+package field
+
+import (
+	"math/bits"
+)
+
+type (
+	// SliceEvaluator checks 64 layouts of a BitField at once.
+	// The layouts are transposed so that each line index becomes one word holding its State in every layout,
+	// which lets the squares and loose matches of all layouts be found with word-wide AND and OR.
+	SliceEvaluator struct {
+		squares [][]int // line indices of every square
+	}
+)
+
+// NewSliceEvaluator returns a SliceEvaluator that only looks for the given squares, given as bit masks.
+// Layouts with a match outside of these squares are never valid.
+func NewSliceEvaluator(squares []uint64) *SliceEvaluator {
+	e := &SliceEvaluator{squares: make([][]int, len(squares))}
+	for j, s := range squares {
+		for ; s != 0; s &= s - 1 {
+			e.squares[j] = append(e.squares[j], bits.TrailingZeros64(s))
+		}
+	}
+	return e
+}
+
+// Evaluate returns a bit mask of the layouts that have between min and max squares
+// and in which every match is part of a square, where bit i stands for layouts[i].
+// The layouts are transposed in place.
+func (e *SliceEvaluator) Evaluate(layouts *[64]uint64, min, max int) uint64 {
+	Transpose(layouts)
+
+	var count [7]uint64 // the number of squares of each layout, one bit per word
+	var covered [64]uint64
+	for _, square := range e.squares {
+		present := ^uint64(0)
+		for _, i := range square {
+			present &= layouts[i]
+		}
+		if present == 0 {
+			continue
+		}
+		for _, i := range square {
+			covered[i] |= present
+		}
+		// add one to the count of the layouts with the square
+		for b, carry := 0, present; carry != 0; b++ {
+			count[b], carry = count[b]^carry, count[b]&carry
+		}
+	}
+
+	loose := uint64(0)
+	for i, l := range layouts {
+		loose |= l &^ covered[i]
+	}
+
+	inRange := uint64(0)
+	for n := min; n <= max && n <= len(e.squares); n++ {
+		equal := ^uint64(0)
+		for b, c := range count {
+			if n>>b&1 == 1 {
+				equal &= c
+			} else {
+				equal &^= c
+			}
+		}
+		inRange |= equal
+	}
+
+	return inRange &^ loose
+}
+
+// Transpose transposes a 64 by 64 bit matrix in place, so that bit j of word i becomes bit i of word j.
+func Transpose(m *[64]uint64) {
+	mask := uint64(0x00000000ffffffff)
+	for j := 32; j != 0; j, mask = j>>1, mask^(mask<<(j>>1)) {
+		for k := 0; k < 64; k = (k + j + 1) &^ j {
+			t := (m[k]>>j ^ m[k+j]) & mask
+			m[k] ^= t << j
+			m[k+j] ^= t
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package field
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranspose(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		var m, transposed [64]uint64
+		for i := range m {
+			m[i] = rnd.Uint64()
+		}
+		transposed = m
+		Transpose(&transposed)
+		for i := range m {
+			for j := range m {
+				assert.Equal(t, m[i]>>j&1, transposed[j]>>i&1)
+			}
+		}
+
+		Transpose(&transposed)
+		assert.Equal(t, m, transposed)
+	}
+}
+
+func TestSliceEvaluator(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, size := range [][2]int{{4, 1}, {4, 3}, {4, 4}, {6, 4}} {
+		// every edge of an empty field is a space, so every square can be completed
+		f := NewBitField(size[0], size[1], nil)
+		all := f.GetMask(Space)
+		squares := f.CompletableSquares(len(Edges(size[0], size[1])))
+		e := NewSliceEvaluator(squares)
+
+		for n := 0; n < 50; n++ {
+			// dense layouts have squares, a few have every match on one
+			var layouts [64]uint64
+			for i := range layouts {
+				layouts[i] = (rnd.Uint64() | rnd.Uint64() | rnd.Uint64()) & all
+				if i%8 == 0 {
+					layouts[i] = squares[rnd.Intn(len(squares))]
+				}
+			}
+			initial := layouts
+
+			for min := 0; min <= len(squares); min++ {
+				for _, max := range []int{min, min + 1, len(squares)} {
+					copied := initial
+					valid := e.Evaluate(&copied, min, max)
+					for i, layout := range initial {
+						f.SetLayout(layout)
+						count, covered := f.CountSquares()
+						expected := covered && count >= min && count <= max
+						assert.Equal(t, expected, valid&(1<<i) != 0, "layout %x in [%d, %d]", layout, min, max)
+					}
+				}
+			}
+		}
+	}
+}
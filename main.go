@@ -1,22 +1,92 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+
 	"github.com/rzamm/matchstick-solver/display"
 	"github.com/rzamm/matchstick-solver/logg"
 	"github.com/rzamm/matchstick-solver/run"
 )
 
+var (
+	shard = flag.String("shard", "", "solve only shard i/n of the removals, like 2/8")
+	out   = flag.String("out", "", "write every solution to this file instead of drawing the first one")
+	merge = flag.String("merge", "", "merge the solution files given as arguments into this file")
+
+	checkpoint = flag.String("checkpoint", "", "save the progress of the search to this file every minute")
+	resume     = flag.Bool("resume", false, "continue the search from the -checkpoint file")
+
+	deterministic = flag.Bool("deterministic", false, "order the solutions by their removal and placement")
+	workers       = flag.Int("workers", 0, "the number of goroutines that search, the number of CPUs if 0")
+	batch         = flag.Int("batch", 0, "the number of removals that a goroutine tries at once")
+	estimate      = flag.Int("estimate", 0, "estimate the time of the search from this many sample removals and stop")
+)
+
 func main() {
+	flag.Parse()
+	if *merge != "" {
+		mergeFiles(*merge, flag.Args())
+		return
+	}
+
 	lvl := run.Lvl19(true)
 
 	runner := run.NewRun(lvl)
 	runner.PrintStats()
 
+	opts := run.Options{}
+	if *shard != "" {
+		var i, n int
+		if _, err := fmt.Sscanf(*shard, "%d/%d", &i, &n); err != nil {
+			panic(err)
+		}
+		opts.Start, opts.End = runner.Shard(i, n)
+		opts.Bounded = true
+	}
+	opts.Checkpoint, opts.Resume = *checkpoint, *resume
+	opts.Deterministic = *deterministic
+	opts.Pool = run.PoolConfig{Workers: *workers, Batch: *batch}
+	opts.OnProgress = func(p run.Progress) {
+		fmt.Fprintf(os.Stderr, "%d out of %d combinations, %.0f per second, %v left, %d solutions\n",
+			p.Processed, p.Total, p.Rate, p.ETA.Round(time.Second), p.Solutions)
+	}
+
+	if *estimate > 0 {
+		e := runner.Estimate(rand.New(rand.NewSource(time.Now().UnixNano())), *estimate, opts)
+		fmt.Printf("estimate %v (%v to %v) on %d workers\n", e.Duration.Round(time.Second),
+			e.Low.Round(time.Second), e.High.Round(time.Second), e.Workers)
+		return
+	}
+
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		written, err := runner.WriteSolutions(context.Background(), f, opts)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println("solutions", written)
+		return
+	}
+
 	logg.Println("Starting Layout: ")
 	display.Draw(lvl.Field)
 	logg.Println("\n\n")
 
-	fs := runner.SolveGame(true)
+	opts.MaxSolutions = 1
+	fs, err := runner.SolveGameContext(context.Background(), opts)
+	if err != nil {
+		panic(err)
+	}
 	if len(fs) == 0 {
 		logg.Println("No Solutions Found")
 	} else {
@@ -28,3 +98,27 @@ func main() {
 
 	logg.Flush()
 }
+
+// mergeFiles merges solution files into one.
+func mergeFiles(to string, from []string) {
+	files := make([]io.Reader, len(from))
+	for i, name := range from {
+		f, err := os.Open(name)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		files[i] = f
+	}
+
+	f, err := os.Create(to)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	merged, err := run.MergeSolutions(f, files...)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("solutions", merged)
+}
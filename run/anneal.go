@@ -0,0 +1,131 @@
+package run
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+// Budget limits a stochastic search by a number of iterations, a duration or both, whichever runs out first.
+// A zero value is no limit, but at least one of them has to be set.
+type Budget struct {
+	Iterations int
+	Duration   time.Duration
+}
+
+const (
+	annealStart = 2.0  // the temperature at the start of SolveAnnealing
+	annealEnd   = 0.05 // the temperature when the budget runs out
+)
+
+// SolveAnnealing searches for solutions with simulated annealing, for fields too big to try every combination.
+// It starts from random removals and placements, then keeps moving one removed match back and removing another,
+// or one placed match to another space, accepting moves that make the layout worse
+// with a probability that shrinks as the budget runs out.
+// A layout scores its distance to the target number of squares plus the number of matches
+// that are not part of a square, so solutions score 0.
+// The search is the same for the same seed of rnd when only the iterations are limited.
+// It returns the solutions found before the budget ran out, which may be none,
+// and stops early once it found opts.MaxSolutions of them.
+func (r *Run) SolveAnnealing(rnd *rand.Rand, budget Budget, opts Options) []FieldI {
+	if budget.Iterations <= 0 && budget.Duration <= 0 {
+		panic("budget has no limit")
+	}
+
+	matches := rnd.Perm(r.matchCount)
+	removed, kept := matches[:r.movable], matches[r.movable:]
+	var placed, free []int
+	if r.gameType == moveGame {
+		spaces := rnd.Perm(r.spaceCount)
+		placed, free = spaces[:r.movable], spaces[r.movable:]
+	}
+	// the search changes its own copy of the field, like the workers of the other searches
+	f := r.field.Copy(false).(FieldI)
+	f.ChangeToState(removed, field.Match, field.Space)
+	f.ChangeToState(placed, field.Space, field.Match)
+
+	solutions := make([]FieldI, 0)
+	seen := make(map[string]interface{})
+	check := func() {
+		removeComb := append([]int(nil), removed...)
+		placeComb := append([]int(nil), placed...)
+		sort.Ints(removeComb)
+		sort.Ints(placeComb)
+		key := fmt.Sprint(removeComb, placeComb)
+		if _, ok := seen[key]; ok || !r.canMove(removeComb, placeComb) {
+			return
+		}
+		seen[key] = nil
+		solutions = append(solutions, f.Copy(true).(FieldI))
+	}
+
+	// swap exchanges a removed or placed match with one that is still in its initial state,
+	// swapping the same indices again undoes it
+	swap := func(changed, unchanged []int, list field.State, a, b int) {
+		f.ChangeOne(changed[a], list, list)
+		f.ChangeOne(unchanged[b], list, !list)
+		changed[a], unchanged[b] = unchanged[b], changed[a]
+	}
+
+	score := r.annealScore(f, len(placed))
+	if score == 0 {
+		check()
+	}
+	start := time.Now()
+	timeProgress := 0.0
+	for i := 0; (budget.Iterations <= 0 || i < budget.Iterations) && !opts.enough(len(solutions)); i++ {
+		if budget.Duration > 0 && i%256 == 0 {
+			elapsed := time.Since(start)
+			if elapsed >= budget.Duration {
+				break
+			}
+			timeProgress = float64(elapsed) / float64(budget.Duration)
+		}
+		progress := timeProgress
+		if budget.Iterations > 0 {
+			progress = math.Max(progress, float64(i)/float64(budget.Iterations))
+		}
+		temperature := annealStart * math.Pow(annealEnd/annealStart, progress)
+
+		changed, unchanged, list := removed, kept, field.Match
+		if len(placed) > 0 && len(free) > 0 && (rnd.Intn(2) == 0 || len(kept) == 0) {
+			changed, unchanged, list = placed, free, field.Space
+		}
+		if len(changed) == 0 || len(unchanged) == 0 {
+			// there is only one layout
+			break
+		}
+		a, b := rnd.Intn(len(changed)), rnd.Intn(len(unchanged))
+		swap(changed, unchanged, list, a, b)
+
+		next := r.annealScore(f, len(placed))
+		if next > score && rnd.Float64() >= math.Exp(float64(score-next)/temperature) {
+			swap(changed, unchanged, list, a, b)
+			continue
+		}
+		score = next
+		if score == 0 {
+			check()
+		}
+	}
+
+	return solutions
+}
+
+// annealScore returns the distance of the number of squares of f to the target,
+// plus the number of matches that are not part of a square.
+func (r *Run) annealScore(f FieldI, placed int) int {
+	count := f.GetSquaresCount()
+	distance := 0
+	if count < r.target.Min {
+		distance = r.target.Min - count
+	} else if count > r.target.Max {
+		distance = count - r.target.Max
+	}
+	matches := r.matchCount - r.movable + placed
+	return distance + matches - f.GetCoveredCount()
+}
@@ -0,0 +1,32 @@
+package run
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveAnnealing(t *testing.T) {
+	for _, lvl := range []*Level{Lvl6(true), multipleSolutionsLevel(false), Lvl16Test(true)} {
+		// the same seed finds the same solutions
+		solutions := NewRun(lvl).SolveAnnealing(rand.New(rand.NewSource(1)), Budget{Iterations: 100000}, Options{})
+		again := NewRun(lvl).SolveAnnealing(rand.New(rand.NewSource(1)), Budget{Iterations: 100000}, Options{})
+		assert.NotEmpty(t, solutions)
+		assert.Equal(t, layouts(solutions), layouts(again))
+	}
+
+	// searches on the same Run at the same time do not change each other's field
+	runner := NewRun(multipleSolutionsLevel(true))
+	expected := layouts(runner.SolveGame(Options{}))
+	results := make(chan []FieldI)
+	for i := 0; i < 4; i++ {
+		go func(seed int64) {
+			results <- runner.SolveAnnealing(rand.New(rand.NewSource(seed)), Budget{Iterations: 200000}, Options{})
+		}(int64(i))
+	}
+	for i := 0; i < 4; i++ {
+		assert.Subset(t, expected, layouts(<-results))
+	}
+	assert.Equal(t, layouts([]FieldI{multipleSolutionsLevel(true).Field}), layouts([]FieldI{runner.field}))
+}
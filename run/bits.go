@@ -0,0 +1,105 @@
+package run
+
+import (
+	"context"
+	"math/bits"
+
+	"github.com/rzamm/matchstick-solver/ec"
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+// eachBitRemoval is eachRemoval for a BitField, without changing the field.
+// The removals of a task are in the order of their combination index, which is lexicographic
+// in the match list. Reversing the bits of a removal and keeping the other matches turns that into
+// increasing order, so the kept matches are walked with Gosper's hack and the removed ones are
+// scattered onto the matches with a Depositor of the match mask.
+// It calls fn with the layout after the removal and the removed matches.
+func (r *Run) eachBitRemoval(ctx context.Context, f *field.BitField, matches ec.Depositor, tp *taskParams,
+	prune bool, fn func(layout, removed uint64)) {
+
+	if r.matchCount == 0 || tp.removeCombIndex >= tp.removeCombEnd {
+		return
+	}
+	initial := f.GetMask(field.Match)
+	all := ^uint64(0) >> (64 - r.matchCount)
+	reverse := func(x uint64) uint64 {
+		return bits.Reverse64(x) >> (64 - r.matchCount)
+	}
+	comb := uint64(0)
+	for _, i := range r.removeCombAt(tp.removeCombIndex) {
+		comb |= 1 << i
+	}
+	kept := reverse(comb) ^ all
+
+	// the index lists are only needed to check the removals against the moves and symmetries
+	check := r.moves != nil || (prune && len(r.symmetries) > 0)
+	for i := tp.removeCombIndex; i < tp.removeCombEnd && ctx.Err() == nil; i++ {
+		if i > tp.removeCombIndex {
+			kept = ec.NextSubset(kept)
+		}
+		removed := matches.Deposit(reverse(kept ^ all))
+		if check {
+			removeComb := listIndices(initial, removed)
+			if !r.canRemove(removeComb) || (prune && !r.isCanonical(removeComb)) {
+				continue
+			}
+		}
+		fn(initial&^removed, removed)
+	}
+}
+
+// eachBitPlacement is eachPlacement for a BitField, without changing the field.
+// It walks the combinations of spaces directly on bit masks with Gosper's hack,
+// scattering each one onto the spaces with a Depositor of the space mask,
+// and calls fn with the layout after placing them on base and the placed matches.
+func (r *Run) eachBitPlacement(base uint64, spaces ec.Depositor, fn func(layout, placed uint64)) {
+	if r.movable == 0 {
+		// the only placement is the empty one
+		fn(base, 0)
+		return
+	}
+	x := uint64(1)<<r.movable - 1
+	for placeCombIndex := 0; placeCombIndex < r.placeCombsTotal && x != 0; placeCombIndex++ {
+		placed := spaces.Deposit(x)
+		fn(base|placed, placed)
+		x = ec.NextSubset(x)
+	}
+}
+
+// eachBitSolution calls fn with the placed matches of every placement on the layout base of a BitField
+// that gives a number of squares within the target, where every match is part of a square.
+// The layouts are checked in batches of 64 with a SliceEvaluator.
+func (r *Run) eachBitSolution(f *field.BitField, base uint64, spaces ec.Depositor, fn func(placed uint64)) {
+	evaluator := field.NewSliceEvaluator(f.CompletableSquaresOf(base, r.movable))
+	var layouts, placements [64]uint64
+	n := 0
+	evaluate := func() {
+		solutions := evaluator.Evaluate(&layouts, r.target.Min, r.target.Max) & (^uint64(0) >> (64 - n))
+		for ; solutions != 0; solutions &= solutions - 1 {
+			fn(placements[bits.TrailingZeros64(solutions)])
+		}
+		n = 0
+	}
+
+	r.eachBitPlacement(base, spaces, func(layout, placed uint64) {
+		layouts[n], placements[n] = layout, placed
+		n++
+		if n == len(layouts) {
+			evaluate()
+		}
+	})
+	if n > 0 {
+		evaluate()
+	}
+}
+
+// listIndices returns the indices in a match or space list of the set bits of comb,
+// where list is the mask of all matches or spaces in the list.
+func listIndices(list, comb uint64) []int {
+	indices := make([]int, 0, bits.OnesCount64(comb))
+	for ; comb != 0; comb &= comb - 1 {
+		lowest := comb & -comb
+		indices = append(indices, bits.OnesCount64(list&(lowest-1)))
+	}
+	return indices
+}
@@ -0,0 +1,73 @@
+package run
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rzamm/matchstick-solver/ec"
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+// blockMoveLevel is blockLevel with a number of movable matches and a required number of squares
+func blockMoveLevel(bit bool, movable, shapesRequired int) *Level {
+	lvl := blockLevel(bit)
+	lvl.Movable = movable
+	lvl.Target = Exactly(shapesRequired)
+	return lvl
+}
+
+func TestEachBitPlacement(t *testing.T) {
+	for movable := 0; movable <= 2; movable++ {
+		// both field types place the same combinations
+		expected := make([]string, 0)
+		runner := NewRun(blockMoveLevel(false, movable, 5))
+		runner.eachPlacement(runner.field, func([]int) {
+			expected = append(expected, layouts([]FieldI{runner.field.Copy(true).(FieldI)})[0])
+		})
+
+		placements := make([]string, 0)
+		runner = NewRun(blockMoveLevel(true, movable, 5))
+		f := runner.field.(*field.BitField)
+		runner.eachBitPlacement(f.GetLayout(), ec.NewDepositor(f.GetMask(field.Space)), func(layout, placed uint64) {
+			solution := f.Copy(true).(*field.BitField)
+			solution.SetLayout(layout)
+			placements = append(placements, layouts([]FieldI{solution})[0])
+		})
+		assert.Len(t, placements, runner.placeCombsTotal)
+		assert.ElementsMatch(t, expected, placements)
+	}
+
+	// with no movable matches the initial layout is the only one
+	for _, bit := range []bool{false, true} {
+		assert.Len(t, NewRun(blockMoveLevel(bit, 0, 5)).SolveGame(Options{}), 1)
+		assert.Empty(t, NewRun(blockMoveLevel(bit, 0, 4)).SolveGame(Options{}))
+	}
+}
+
+func TestEachBitRemoval(t *testing.T) {
+	for _, newLevel := range []func(bool) *Level{Lvl16Test, blockLevel, multipleSolutionsLevel} {
+		runner := NewRun(newLevel(true))
+		f := runner.field.(*field.BitField)
+		matches := f.GetMask(field.Match)
+		ranges := []taskParams{{0, runner.removeCombsTotal}, {runner.removeCombsTotal / 3, runner.removeCombsTotal}}
+		for _, tp := range ranges {
+			for _, prune := range []bool{false, true} {
+				// the removals are the same ones, in the same order, as the ones of eachRemoval
+				expected := make([][]int, 0)
+				runner.eachRemoval(context.Background(), f, &tp, prune, func(removeComb []int) {
+					expected = append(expected, append([]int(nil), removeComb...))
+				})
+
+				removals := make([][]int, 0)
+				runner.eachBitRemoval(context.Background(), f, ec.NewDepositor(matches), &tp, prune,
+					func(layout, removed uint64) {
+						assert.Equal(t, matches&^removed, layout)
+						removals = append(removals, listIndices(matches, removed))
+					})
+				assert.Equal(t, expected, removals)
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package run
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+// ErrLevelChanged is returned when resuming from a checkpoint of a different level or range of removals.
+var ErrLevelChanged = errors.New("checkpoint is of a different level")
+
+// defaultCheckpointInterval is the time between checkpoints if Options.CheckpointInterval is not set.
+const defaultCheckpointInterval = time.Minute
+
+type (
+	// checkpoint is the progress of a search as it is saved to a file.
+	checkpoint struct {
+		Level     string // the fingerprint of the Run
+		Start     int    // the first removal combination index of the search
+		End       int    // the removal combination index that the search stops before
+		Done      int    // every removal with a lower combination index is done
+		Solutions []combSolution
+	}
+
+	// combSolution is a solution as the combination of matches it removes and spaces it places.
+	combSolution struct {
+		Remove []int
+		Place  []int
+	}
+
+	// checkpointer saves the progress of a search to the checkpoint file of its Options.
+	checkpointer struct {
+		path     string
+		interval time.Duration
+		saved    time.Time
+		state    checkpoint
+	}
+)
+
+// Fingerprint identifies the level of a Run, it changes if anything changes that the solutions depend on.
+func (r *Run) Fingerprint() string {
+	level := fmt.Sprint(r.gameType, r.movable, *r.target, r.moves, r.field.GetWidth(), r.field.GetHeight(),
+		r.field.GetEdges(field.Match), r.field.GetEdges(field.Space))
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(level)))
+}
+
+// newCheckpointer returns a checkpointer for the range of removals [start, end) of opts.
+// If opts.Resume is set and the checkpoint file exists, it continues from there,
+// unless the checkpoint is of a different level or range.
+func (r *Run) newCheckpointer(opts Options, start, end int) (*checkpointer, error) {
+	c := &checkpointer{
+		path:     opts.Checkpoint,
+		interval: opts.CheckpointInterval,
+		saved:    time.Now(),
+		state: checkpoint{
+			Level:     r.Fingerprint(),
+			Start:     start,
+			End:       end,
+			Done:      start,
+			Solutions: make([]combSolution, 0),
+		},
+	}
+	if c.interval <= 0 {
+		c.interval = defaultCheckpointInterval
+	}
+	if c.path == "" || !opts.Resume {
+		return c, nil
+	}
+
+	data, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved checkpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("bad checkpoint %s: %v", c.path, err)
+	}
+	if saved.Level != c.state.Level || saved.Start != start || saved.End != end {
+		return nil, ErrLevelChanged
+	}
+	c.state = saved
+	return c, nil
+}
+
+// resume calls fn with the solutions of the checkpoint, marking them as seen,
+// and returns false as soon as fn does.
+// initial is a copy of the field in its initial state, it is used to create the solutions.
+func (c *checkpointer) resume(initial FieldI, seen map[string]interface{}, fn func(*taskReturn) bool) bool {
+	for _, s := range c.state.Solutions {
+		seen[fmt.Sprint(s.Remove, s.Place)] = nil
+		result := &taskReturn{
+			f:          layout(initial, s.Remove, s.Place),
+			removeComb: s.Remove,
+			placeComb:  s.Place,
+		}
+		if !fn(result) {
+			return false
+		}
+	}
+	return true
+}
+
+// record returns fn, adding every solution that it is called with to the checkpoint.
+func (c *checkpointer) record(fn func(*taskReturn) bool) func(*taskReturn) bool {
+	return func(result *taskReturn) bool {
+		c.state.Solutions = append(c.state.Solutions, combSolution{Remove: result.removeComb, Place: result.placeComb})
+		return fn(result)
+	}
+}
+
+// update saves the checkpoint like save, if the interval passed since the last one.
+func (c *checkpointer) update(done int) error {
+	if c.path == "" || time.Since(c.saved) < c.interval {
+		return nil
+	}
+	return c.save(done)
+}
+
+// save saves the checkpoint, where every removal with a combination index below done is done.
+// The file is replaced at once, so that a crash while saving leaves the previous checkpoint.
+func (c *checkpointer) save(done int) error {
+	if c.path == "" {
+		return nil
+	}
+	c.state.Done = done
+	c.saved = time.Now()
+	data, err := json.Marshal(&c.state)
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
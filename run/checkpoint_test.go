@@ -0,0 +1,100 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checkpoint.json")
+
+	for _, newLevel := range []func(bool) *Level{Lvl6, multipleSolutionsLevel, Lvl16Test} {
+		expected := NewRun(newLevel(true)).SolveGame(Options{})
+
+		// stop after the first solution, then resume
+		solutions, err := NewRun(newLevel(true)).SolveGameContext(context.Background(),
+			Options{MaxSolutions: 1, Checkpoint: path, CheckpointInterval: time.Nanosecond})
+		assert.NoError(t, err)
+		assert.Len(t, solutions, 1)
+		saved := readCheckpoint(t, path)
+		assert.Len(t, saved.Solutions, 1)
+
+		solutions, err = NewRun(newLevel(false)).SolveGameContext(context.Background(),
+			Options{Checkpoint: path, Resume: true})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, layouts(expected), layouts(solutions))
+		saved = readCheckpoint(t, path)
+		assert.Equal(t, saved.End, saved.Done)
+		assert.Len(t, saved.Solutions, len(expected))
+
+		// resuming a finished search returns its solutions
+		solutions, err = NewRun(newLevel(true)).SolveGameContext(context.Background(),
+			Options{Checkpoint: path, Resume: true})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, layouts(expected), layouts(solutions))
+
+		// a search that was cancelled before it started resumes from the start
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = NewRun(newLevel(true)).SolveGameContext(ctx, Options{Checkpoint: path})
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, 0, readCheckpoint(t, path).Done)
+		solutions, err = NewRun(newLevel(true)).SolveGameContext(context.Background(),
+			Options{Checkpoint: path, Resume: true})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, layouts(expected), layouts(solutions))
+	}
+
+	// the checkpoint is of Lvl16Test
+	_, err = NewRun(Lvl6(true)).SolveGameContext(context.Background(), Options{Checkpoint: path, Resume: true})
+	assert.Equal(t, ErrLevelChanged, err)
+	_, err = NewRun(Lvl16Test(true)).SolveGameContext(context.Background(),
+		Options{Checkpoint: path, Resume: true, Start: 1})
+	assert.Equal(t, ErrLevelChanged, err)
+}
+
+func TestCheckpointBuffered(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checkpoint.json")
+
+	// the tasks that finish after the stop are done, but their solutions are dropped
+	expected := NewRun(blockLevel(true)).SolveGame(Options{})
+	pools := []PoolConfig{{Buffer: 8}, {Buffer: 64, Batch: 1}, {Workers: 1, Buffer: 64, Batch: 1}}
+	for _, pool := range pools {
+		for _, deterministic := range []bool{false, true} {
+			for i := 0; i < 20; i++ {
+				solutions, err := NewRun(blockLevel(true)).SolveGameContext(context.Background(), Options{
+					MaxSolutions: 1, Deterministic: deterministic, Pool: pool,
+					Checkpoint: path, CheckpointInterval: time.Nanosecond,
+				})
+				assert.NoError(t, err)
+				assert.Len(t, solutions, 1)
+
+				solutions, err = NewRun(blockLevel(true)).SolveGameContext(context.Background(),
+					Options{Deterministic: deterministic, Pool: pool, Checkpoint: path, Resume: true})
+				assert.NoError(t, err)
+				assert.ElementsMatch(t, layouts(expected), layouts(solutions), "%+v", pool)
+			}
+		}
+	}
+}
+
+func readCheckpoint(t *testing.T, path string) checkpoint {
+	var c checkpoint
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &c))
+	return c
+}
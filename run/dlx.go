@@ -0,0 +1,329 @@
+package run
+
+import (
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+type (
+	// dlxNode is a node of the dancing links matrix, with a column for every edge and a row for every square.
+	// Column headers are nodes as well, they are linked into the root's row while their edge is unresolved.
+	dlxNode struct {
+		left, right, up, down *dlxNode
+		column                *dlxNode
+		square                int // the row of a node
+		edge                  int // the line index of a column
+		size                  int // the number of rows left in a column
+	}
+
+	// cover searches for sets of squares whose edges together are exactly the matches of a solution.
+	// Every initial match is an edge that has to be resolved, either by being part of a chosen square
+	// or by being removed. Every other edge that is part of a chosen square is a placed match.
+	cover struct {
+		r         *Run
+		root      *dlxNode
+		squares   [][]int
+		isMatch   []bool // initial matches by line index
+		listIndex []int  // index in the match or space list by line index
+		covered   []int  // the number of chosen squares that each edge is part of
+		isRemoved []bool
+		isChosen  []bool
+		removed   int
+		placed    int
+		chosen    int
+		placeable int // the number of matches that have to be placed
+		seen      map[string]interface{}
+		found     func(FieldI) bool
+	}
+)
+
+// SolveDLX finds the same solutions as SolveGame, by choosing sets of squares whose edges together
+// are the remaining matches, instead of trying every combination of matches and spaces.
+// The squares are chosen with dancing links like an exact cover problem, except that squares may share edges.
+// It does not depend on the size of the field, so it also solves fields that are too big for a BitField.
+func (r *Run) SolveDLX(opts Options) []FieldI {
+	w, h := r.field.GetWidth(), r.field.GetHeight()
+	edges := len(field.Edges(w, h))
+	c := &cover{
+		r:         r,
+		root:      &dlxNode{},
+		squares:   field.Squares(w, h),
+		isMatch:   make([]bool, edges),
+		listIndex: make([]int, edges),
+		covered:   make([]int, edges),
+		isRemoved: make([]bool, edges),
+		seen:      make(map[string]interface{}),
+	}
+	c.isChosen = make([]bool, len(c.squares))
+	if r.gameType == moveGame {
+		c.placeable = r.movable
+	}
+	for i, e := range r.field.GetEdges(field.Match) {
+		c.isMatch[e.Index(w, h)] = true
+		c.listIndex[e.Index(w, h)] = i
+	}
+	for i, e := range r.field.GetEdges(field.Space) {
+		c.listIndex[e.Index(w, h)] = i
+	}
+
+	// link the headers, only initial matches have to be resolved
+	c.root.left, c.root.right = c.root, c.root
+	columns := make([]*dlxNode, edges)
+	for i := range columns {
+		col := &dlxNode{edge: i}
+		col.up, col.down, col.column = col, col, col
+		col.left, col.right = col, col
+		if c.isMatch[i] {
+			col.left, col.right = c.root.left, c.root
+			c.root.left.right = col
+			c.root.left = col
+		}
+		columns[i] = col
+	}
+	// link the rows
+	for j, square := range c.squares {
+		var first *dlxNode
+		for _, i := range square {
+			col := columns[i]
+			n := &dlxNode{column: col, square: j, up: col.up, down: col}
+			col.up.down = n
+			col.up = n
+			col.size++
+			if first == nil {
+				first = n
+				n.left, n.right = n, n
+			} else {
+				n.left, n.right = first.left, first
+				first.left.right = n
+				first.left = n
+			}
+		}
+	}
+
+	solutions := make([]FieldI, 0)
+	c.found = func(f FieldI) bool {
+		solutions = append(solutions, f)
+		return !opts.enough(len(solutions))
+	}
+	c.search()
+
+	return solutions
+}
+
+// search resolves the initial match with the fewest squares left, it returns false to stop searching.
+func (c *cover) search() bool {
+	if c.root.right == c.root {
+		return c.extend(0)
+	}
+
+	col := c.root.right
+	for n := col.right; n != c.root; n = n.right {
+		if n.size < col.size {
+			col = n
+		}
+	}
+
+	// choose one of the squares that the edge is part of
+	// every square that was tried is hidden from the next ones, so that each set of squares is chosen once
+	hidden := make([]*dlxNode, 0, col.size)
+	for n := col.down; n != col && c.chosen < c.r.target.Max; n = n.down {
+		c.choose(n)
+		c.hideRow(n)
+		hidden = append(hidden, n)
+		if c.placed <= c.placeable && !c.search() {
+			c.unchoose(n)
+			c.unhideRows(hidden)
+			return false
+		}
+		c.unchoose(n)
+	}
+	c.unhideRows(hidden)
+
+	// or remove the match from the edge, and hide every square that it is part of
+	if c.removed < c.r.movable {
+		c.removed++
+		c.isRemoved[col.edge] = true
+		c.coverColumn(col)
+		hidden = hidden[:0]
+		for n := col.down; n != col; n = n.down {
+			c.hideRow(n)
+			hidden = append(hidden, n)
+		}
+		ok := c.search()
+		c.unhideRows(hidden)
+		c.uncoverColumn(col)
+		c.isRemoved[col.edge] = false
+		c.removed--
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// extend adds squares that are made of placed matches and the edges of the chosen squares,
+// trying squares from index from onwards, it returns false to stop searching.
+func (c *cover) extend(from int) bool {
+	if c.removed == c.r.movable && c.placed == c.placeable && !c.leaf() {
+		return false
+	}
+
+	for j := from; j < len(c.squares) && c.chosen < c.r.target.Max; j++ {
+		if c.isChosen[j] {
+			continue
+		}
+		placements := 0
+		for _, i := range c.squares[j] {
+			if c.isRemoved[i] {
+				placements = c.placeable + 1
+				break
+			}
+			if c.covered[i] == 0 {
+				placements++
+			}
+		}
+		if placements == 0 || c.placed+placements > c.placeable {
+			continue
+		}
+
+		c.add(j)
+		ok := c.extend(j + 1)
+		c.drop(j)
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// leaf checks the layout of the chosen squares and sends it if it is a new solution,
+// it returns false to stop searching.
+func (c *cover) leaf() bool {
+	key := make([]byte, len(c.covered))
+	for i, n := range c.covered {
+		key[i] = '0'
+		if n > 0 {
+			key[i] = '1'
+		}
+	}
+	if _, ok := c.seen[string(key)]; ok {
+		return true
+	}
+	c.seen[string(key)] = nil
+
+	// the layout may have more squares than were chosen
+	squares := 0
+	for _, square := range c.squares {
+		present := true
+		for _, i := range square {
+			if c.covered[i] == 0 {
+				present = false
+				break
+			}
+		}
+		if present {
+			squares++
+		}
+	}
+	if !c.r.target.Contains(squares) {
+		return true
+	}
+
+	removeComb := make([]int, 0, c.r.movable)
+	placeComb := make([]int, 0, c.placeable)
+	for i, n := range c.covered {
+		if c.isMatch[i] && n == 0 {
+			removeComb = append(removeComb, c.listIndex[i])
+		} else if !c.isMatch[i] && n > 0 {
+			placeComb = append(placeComb, c.listIndex[i])
+		}
+	}
+	if !c.r.canMove(removeComb, placeComb) {
+		return true
+	}
+	return c.found(layout(c.r.field, removeComb, placeComb))
+}
+
+// choose adds the square of a node, resolving the initial matches that are part of it.
+func (c *cover) choose(row *dlxNode) {
+	c.add(row.square)
+	for n := row; ; n = n.right {
+		if c.isMatch[n.column.edge] && c.covered[n.column.edge] == 1 {
+			c.coverColumn(n.column)
+		}
+		if n.right == row {
+			break
+		}
+	}
+}
+
+func (c *cover) unchoose(row *dlxNode) {
+	for n := row.left; ; n = n.left {
+		if c.isMatch[n.column.edge] && c.covered[n.column.edge] == 1 {
+			c.uncoverColumn(n.column)
+		}
+		if n == row {
+			break
+		}
+	}
+	c.drop(row.square)
+}
+
+// add adds a square to the chosen squares, counting the matches that it places.
+func (c *cover) add(square int) {
+	c.isChosen[square] = true
+	c.chosen++
+	for _, i := range c.squares[square] {
+		c.covered[i]++
+		if c.covered[i] == 1 && !c.isMatch[i] {
+			c.placed++
+		}
+	}
+}
+
+func (c *cover) drop(square int) {
+	for _, i := range c.squares[square] {
+		if c.covered[i] == 1 && !c.isMatch[i] {
+			c.placed--
+		}
+		c.covered[i]--
+	}
+	c.chosen--
+	c.isChosen[square] = false
+}
+
+func (c *cover) coverColumn(col *dlxNode) {
+	col.right.left = col.left
+	col.left.right = col.right
+}
+
+func (c *cover) uncoverColumn(col *dlxNode) {
+	col.right.left = col
+	col.left.right = col
+}
+
+// hideRow unlinks the nodes of a row from their columns.
+func (c *cover) hideRow(row *dlxNode) {
+	for n := row; ; n = n.right {
+		n.up.down = n.down
+		n.down.up = n.up
+		n.column.size--
+		if n.right == row {
+			break
+		}
+	}
+}
+
+// unhideRows links the nodes of hidden rows back into their columns, in reverse order.
+func (c *cover) unhideRows(rows []*dlxNode) {
+	for i := len(rows) - 1; i >= 0; i-- {
+		row := rows[i]
+		for n := row.left; ; n = n.left {
+			n.column.size++
+			n.up.down = n
+			n.down.up = n
+			if n == row {
+				break
+			}
+		}
+	}
+}
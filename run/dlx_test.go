@@ -0,0 +1,20 @@
+package run
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+func TestSolveDLX_Huge(t *testing.T) {
+	// a square on a field that is far too big for a BitField, it can be moved onto any square not next to it
+	lvl := &Level{
+		Field:          field.NewField(12, 12, 0, placeSquare(0, 0)),
+		GameType:       moveGame,
+		Movable:        4,
+		ShapesRequired: 1,
+	}
+	assert.Len(t, NewRun(lvl).SolveDLX(Options{}), 12*12-3)
+}
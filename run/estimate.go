@@ -0,0 +1,78 @@
+package run
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"runtime"
+	"time"
+)
+
+// estimateZ is the z-score of the 95% confidence interval of an Estimate.
+const estimateZ = 1.96
+
+// Estimate is a projection of the wall-clock time that solving a Run takes,
+// from the time that a random sample of its removals takes.
+type Estimate struct {
+	Samples      int           // the number of removals that were timed
+	Combinations int           // the combinations in the range of removals of the search
+	Workers      int           // the number of removals that are tried at the same time
+	PerRemoval   time.Duration // the mean time of a removal in the sample
+	Duration     time.Duration // the projected time of the search
+	Low, High    time.Duration // the 95% confidence interval of Duration
+}
+
+// Estimate times the search of samples random removals in the range of opts, on the backend of the field,
+// and projects the time of the whole search from them, with its workers on the available cores.
+// Removals that are pruned take almost no time, so the more of them there are,
+// the more samples are needed for a narrow confidence interval. The field is not changed.
+func (r *Run) Estimate(rnd *rand.Rand, samples int, opts Options) Estimate {
+	if samples < 2 {
+		panic("estimate needs at least 2 samples")
+	}
+	var task Task[*taskParams, *taskReturn]
+	switch r.gameType {
+	case removeGame:
+		task = r.removeTasks()()
+	case moveGame:
+		task = r.moveTasks(true)()
+	default:
+		panic("Unknown Game Type")
+	}
+
+	start, end := opts.removals(r.removeCombsTotal)
+	e := Estimate{
+		Samples:      samples,
+		Combinations: (end - start) * (r.totalCombinations / r.removeCombsTotal),
+		Workers:      opts.Pool.workers(),
+	}
+	if e.Workers > runtime.NumCPU() {
+		e.Workers = runtime.NumCPU()
+	}
+	if start >= end {
+		return e
+	}
+
+	// the mean and variance of the seconds that a removal takes, with Welford's algorithm
+	mean, squares := 0.0, 0.0
+	ignore := func(*taskReturn) bool { return true }
+	for i := 0; i < samples; i++ {
+		index := start + rnd.Intn(end-start)
+		began := time.Now()
+		_ = task(context.Background(), &taskParams{removeCombIndex: index, removeCombEnd: index + 1}, ignore)
+		seconds := time.Since(began).Seconds()
+
+		delta := seconds - mean
+		mean += delta / float64(i+1)
+		squares += delta * (seconds - mean)
+	}
+	margin := estimateZ * math.Sqrt(squares/float64(samples-1)/float64(samples))
+
+	// the removals are spread over the workers
+	scale := float64(end-start) / float64(e.Workers) * float64(time.Second)
+	e.PerRemoval = time.Duration(mean * float64(time.Second))
+	e.Duration = time.Duration(mean * scale)
+	e.Low = time.Duration(math.Max(mean-margin, 0) * scale)
+	e.High = time.Duration((mean + margin) * scale)
+	return e
+}
@@ -0,0 +1,33 @@
+package run
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimate(t *testing.T) {
+	for _, newLevel := range []func(bool) *Level{Lvl6, Lvl16Test} {
+		for _, bit := range []bool{false, true} {
+			runner := NewRun(newLevel(bit))
+			e := runner.Estimate(rand.New(rand.NewSource(1)), 20, Options{})
+			assert.Equal(t, 20, e.Samples)
+			assert.Equal(t, runner.totalCombinations, e.Combinations)
+			assert.Greater(t, int64(e.Duration), int64(0))
+			assert.LessOrEqual(t, int64(e.Low), int64(e.Duration))
+			assert.LessOrEqual(t, int64(e.Duration), int64(e.High))
+
+			// the field is not changed
+			assert.ElementsMatch(t, layouts(NewRun(newLevel(bit)).SolveGame(Options{})),
+				layouts(runner.SolveGame(Options{})))
+		}
+	}
+
+	// one worker takes as long as every removal together
+	runner := NewRun(Lvl16Test(true))
+	e := runner.Estimate(rand.New(rand.NewSource(1)), 20, Options{Start: 10, End: 30, Pool: PoolConfig{Workers: 1}})
+	assert.Equal(t, 1, e.Workers)
+	assert.Equal(t, 20*runner.placeCombsTotal, e.Combinations)
+	assert.InDelta(t, float64(20*e.PerRemoval), float64(e.Duration), 100)
+}
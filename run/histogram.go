@@ -0,0 +1,73 @@
+package run
+
+import (
+	"context"
+)
+
+// Histogram counts the layouts that can be reached by moving or removing the movable matches,
+// grouped by their number of squares, ignoring the Target.
+// The count of layouts with n squares is at index n, only layouts where every match is part
+// of a square are counted.
+// It uses the workers and the range of removals of opts.
+// It stops early once ctx is done, returning the error of ctx, or the error of a worker that failed.
+func (r *Run) Histogram(ctx context.Context, opts Options) ([]int, error) {
+	if r.gameType != removeGame && r.gameType != moveGame {
+		panic("Unknown Game Type")
+	}
+
+	// this task counts the squares of every layout of its removals and sends its own histogram
+	newTask := func() Task[*taskParams, *taskReturn] {
+		f := r.field.Copy(false).(FieldI)
+		return func(ctx context.Context, tp *taskParams, output func(*taskReturn) bool) error {
+			histogram := make([]int, 0)
+			r.eachLayout(ctx, f, tp, func([]int, []int) {
+				if count, covered := f.CountSquares(); covered {
+					histogram = addToHistogram(histogram, count, 1)
+				}
+			})
+			output(&taskReturn{histogram: histogram})
+			return nil
+		}
+	}
+	start, end := opts.removals(r.removeCombsTotal)
+	pool := NewPool(ctx, opts.Pool, newTask)
+	go r.sendBatches(pool, start, end, opts.Pool.batch(), newProgress(start))
+
+	histogram := make([]int, 0)
+	for result := range pool.Outputs() {
+		for count, layouts := range result.histogram {
+			histogram = addToHistogram(histogram, count, layouts)
+		}
+	}
+	if err := pool.Err(); err != nil {
+		return histogram, err
+	}
+
+	return histogram, ctx.Err()
+}
+
+// eachLayout calls fn with every layout that the removals of a task reach on f, ignoring the Target.
+// In the move game these are the place combinations of every removal whose matches can be moved there,
+// in the remove game the removals themselves, with an empty place combination.
+func (r *Run) eachLayout(ctx context.Context, f FieldI, tp *taskParams, fn func(removeComb, placeComb []int)) {
+	r.eachRemoval(ctx, f, tp, false, func(removeComb []int) {
+		if r.gameType == removeGame {
+			fn(removeComb, nil)
+			return
+		}
+		r.eachPlacement(f, func(placeComb []int) {
+			if r.canMove(removeComb, placeComb) {
+				fn(removeComb, placeComb)
+			}
+		})
+	})
+}
+
+// addToHistogram adds layouts to the count at index n, growing the histogram if needed.
+func addToHistogram(histogram []int, n, layouts int) []int {
+	for len(histogram) <= n {
+		histogram = append(histogram, 0)
+	}
+	histogram[n] += layouts
+	return histogram
+}
@@ -14,12 +14,16 @@ const (
 
 // A Level describes an initial state, a game type, the number of removable/movable matches
 // and the number of shapes required.
+// Target replaces ShapesRequired with a range of shapes when it is set.
+// MoveModel restricts where a moved match may go, nil allows any space (FreeMove).
 //noinspection GoUnnecessarilyExportedIdentifiers
 type Level struct {
 	Field          FieldI
 	GameType       gameType
 	Movable        int
 	ShapesRequired int
+	Target         *Target
+	MoveModel      MoveModel
 }
 
 // Lvl6 represents level 6.
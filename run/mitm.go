@@ -0,0 +1,187 @@
+package run
+
+import (
+	"fmt"
+	"math/bits"
+
+	"gonum.org/v1/gonum/stat/combin"
+
+	"github.com/rzamm/matchstick-solver/ec"
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+// SolveMeetInTheMiddle finds the same solutions as SolveGame without trying every removal with every placement.
+// A square is present after a move if none of its matches were removed and all of its spaces were placed,
+// so the placements are indexed once by the set of squares that they complete, keeping only the ones whose
+// placed matches are all on those squares. Every removal then looks up the sets whose squares, together with
+// the ones it leaves completable, are within the target and cover every match that is left,
+// and only tries the placements of those.
+// Like a BitField, it only works for fields that have at most 64 edges and 64 squares.
+func (r *Run) SolveMeetInTheMiddle(opts Options) []FieldI {
+	w, h := r.field.GetWidth(), r.field.GetHeight()
+	edges := len(field.Edges(w, h))
+	squareEdges := field.Squares(w, h)
+	if edges > 64 {
+		panic(fmt.Sprintf("cannot fit field with %d spaces into int64", edges))
+	}
+	if len(squareEdges) > 64 {
+		panic(fmt.Sprintf("cannot fit field with %d squares into int64", len(squareEdges)))
+	}
+
+	// the masks of every square, the initial matches and the initial spaces
+	squares := make([]uint64, len(squareEdges))
+	for j, square := range squareEdges {
+		for _, i := range square {
+			squares[j] |= 1 << i
+		}
+	}
+	listIndex := make([]int, edges)
+	matchList := make([]uint64, 0)
+	spaceList := make([]uint64, 0)
+	start, spaces := uint64(0), uint64(0)
+	for i, e := range r.field.GetEdges(field.Match) {
+		listIndex[e.Index(w, h)] = i
+		matchList = append(matchList, 1<<e.Index(w, h))
+		start |= 1 << e.Index(w, h)
+	}
+	for i, e := range r.field.GetEdges(field.Space) {
+		listIndex[e.Index(w, h)] = i
+		spaceList = append(spaceList, 1<<e.Index(w, h))
+		spaces |= 1 << e.Index(w, h)
+	}
+	placeable := 0
+	if r.gameType == moveGame {
+		placeable = r.movable
+	}
+
+	// completable returns the squares that have none of the removed matches
+	completable := func(removed uint64) uint64 {
+		set := uint64(0)
+		for j, s := range squares {
+			if s&removed == 0 {
+				set |= 1 << j
+			}
+		}
+		return set
+	}
+	// completed returns the squares whose spaces are all placed
+	completed := func(placed uint64) uint64 {
+		set := uint64(0)
+		for j, s := range squares {
+			if s&spaces&^placed == 0 {
+				set |= 1 << j
+			}
+		}
+		return set
+	}
+	// cover returns the edges of a set of squares
+	cover := func(set uint64) uint64 {
+		covered := uint64(0)
+		for j, s := range squares {
+			if set&(1<<j) != 0 {
+				covered |= s
+			}
+		}
+		return covered
+	}
+
+	// a placed match has to be part of a present square, which is one of the squares the placement completes,
+	// so the placements that place a match outside of all of those can never be part of a solution
+	placements := newPlacementIndex()
+	eachSubset(spaceList, placeable, func(placed uint64) bool {
+		if set := completed(placed); placed&^cover(set) == 0 {
+			placements.add(set, cover(set), placed)
+		}
+		return true
+	})
+
+	solutions := make([]FieldI, 0)
+	eachSubset(matchList, r.movable, func(removed uint64) bool {
+		removalSet := completable(removed)
+		left := start &^ removed
+		if left&^cover(removalSet) != 0 {
+			return true
+		}
+
+		for _, placementSet := range placements.sets {
+			// every match that is left or placed has to be part of a present square
+			present := removalSet & placementSet
+			if !r.target.Contains(bits.OnesCount64(present)) || left&^placements.covers[placementSet] != 0 {
+				continue
+			}
+			covered := cover(present)
+			if left&^covered != 0 {
+				continue
+			}
+			for _, placed := range placements.members[placementSet] {
+				if placed&^covered != 0 {
+					continue
+				}
+
+				removeComb := make([]int, 0, r.movable)
+				placeComb := make([]int, 0, placeable)
+				for i := 0; i < edges; i++ {
+					if removed&(1<<i) != 0 {
+						removeComb = append(removeComb, listIndex[i])
+					} else if placed&(1<<i) != 0 {
+						placeComb = append(placeComb, listIndex[i])
+					}
+				}
+				if !r.canMove(removeComb, placeComb) {
+					continue
+				}
+				solutions = append(solutions, layout(r.field, removeComb, placeComb))
+				if opts.enough(len(solutions)) {
+					return false
+				}
+			}
+		}
+		return true
+	})
+
+	return solutions
+}
+
+// placementIndex holds the placements of SolveMeetInTheMiddle as masks of edges,
+// grouped by the set of squares that they complete.
+type placementIndex struct {
+	sets    []uint64            // the sets of squares in the order that they were first added
+	covers  map[uint64]uint64   // the edges of the squares of every set
+	members map[uint64][]uint64 // the placements of every set
+}
+
+func newPlacementIndex() *placementIndex {
+	return &placementIndex{
+		covers:  make(map[uint64]uint64),
+		members: make(map[uint64][]uint64),
+	}
+}
+
+// add adds a placement to the group of the set of squares that it completes, whose edges are covered.
+func (p *placementIndex) add(set, covered, placed uint64) {
+	if _, ok := p.members[set]; !ok {
+		p.sets = append(p.sets, set)
+		p.covers[set] = covered
+	}
+	p.members[set] = append(p.members[set], placed)
+}
+
+// eachSubset calls fn with the union of every combination of k masks from a list, until fn returns false.
+func eachSubset(list []uint64, k int, fn func(uint64) bool) {
+	comb := make([]int, k)
+	// init comb to [0, 1 , 2 ... k-1]
+	for i := 0; i < k; i++ {
+		comb[i] = i
+	}
+	total := combin.Binomial(len(list), k)
+	for index := 0; index < total; index++ {
+		mask := uint64(0)
+		for _, c := range comb {
+			mask |= list[c]
+		}
+		if !fn(mask) {
+			return
+		}
+		ec.NextCombination(comb, len(list), k)
+	}
+}
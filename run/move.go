@@ -0,0 +1,138 @@
+package run
+
+import "github.com/rzamm/matchstick-solver/field"
+
+type (
+	// MoveModel decides where a single match may be moved to in one move.
+	// Every moved match makes exactly one move, from its place in the initial layout onto an edge that is
+	// empty in the initial layout. So under a restricted model, a match cannot move into the place
+	// that another match leaves, even if the two moves one after the other would be legal.
+	MoveModel interface {
+		// CanMove returns true if a match on from can be moved onto to.
+		CanMove(from, to field.Edge) bool
+	}
+
+	freeMove  struct{}
+	pivotMove struct{}
+	slideMove struct{}
+)
+
+//noinspection GoUnnecessarilyExportedIdentifiers
+var (
+	// FreeMove picks a match up and drops it anywhere on the field.
+	FreeMove MoveModel = freeMove{}
+	// PivotMove turns a match 90 degrees around one of its ends.
+	PivotMove MoveModel = pivotMove{}
+	// SlideMove slides a match by one unit along its own line.
+	SlideMove MoveModel = slideMove{}
+)
+
+func (freeMove) CanMove(_, _ field.Edge) bool {
+	return true
+}
+
+func (pivotMove) CanMove(from, to field.Edge) bool {
+	if from.Vertical == to.Vertical {
+		return false
+	}
+	// a horizontal edge and a vertical edge meet at an end of both when
+	// the vertical edge starts or ends at one of the horizontal edge's ends
+	h, v := from, to
+	if from.Vertical {
+		h, v = to, from
+	}
+	return (v.X == h.X || v.X == h.X+1) && (v.Y == h.Y || v.Y == h.Y-1)
+}
+
+func (slideMove) CanMove(from, to field.Edge) bool {
+	if from.Vertical != to.Vertical {
+		return false
+	}
+	if from.Vertical {
+		return from.X == to.X && (from.Y == to.Y+1 || from.Y == to.Y-1)
+	}
+	return from.Y == to.Y && (from.X == to.X+1 || from.X == to.X-1)
+}
+
+// moveTable returns which matches can be moved onto which spaces of a field, indexed by [match][space].
+// It returns nil if every match can be moved onto every space.
+func moveTable(f FieldI, model MoveModel) [][]bool {
+	if model == nil || model == FreeMove {
+		return nil
+	}
+
+	matches := f.GetEdges(field.Match)
+	spaces := f.GetEdges(field.Space)
+	table := make([][]bool, len(matches))
+	for i, m := range matches {
+		table[i] = make([]bool, len(spaces))
+		for j, s := range spaces {
+			table[i][j] = model.CanMove(m, s)
+		}
+	}
+	return table
+}
+
+// canRemove returns false if one of the removed matches cannot be moved onto any space.
+func (r *Run) canRemove(removeComb []int) bool {
+	if r.moves == nil {
+		return true
+	}
+	for _, m := range removeComb {
+		movable := false
+		for _, ok := range r.moves[m] {
+			if ok {
+				movable = true
+				break
+			}
+		}
+		if !movable {
+			return false
+		}
+	}
+	return true
+}
+
+// canMove returns true if every removed match can be moved onto a different placed space.
+func (r *Run) canMove(removeComb, placeComb []int) bool {
+	return r.moves == nil || r.assignMoves(removeComb, placeComb) != nil
+}
+
+// assignMoves returns which removed match is moved onto each placed space, as an index into removeComb
+// for every index into placeComb, or nil if the removed matches cannot all be moved onto them.
+// This is a bipartite matching between removeComb and placeComb, found with augmenting paths.
+func (r *Run) assignMoves(removeComb, placeComb []int) []int {
+	// placedBy[j] is the index into removeComb of the match moved onto placeComb[j], or -1
+	placedBy := make([]int, len(placeComb))
+	if r.moves == nil {
+		for j := range placedBy {
+			placedBy[j] = j
+		}
+		return placedBy
+	}
+	for j := range placedBy {
+		placedBy[j] = -1
+	}
+
+	var augment func(i int, seen []bool) bool
+	augment = func(i int, seen []bool) bool {
+		for j, s := range placeComb {
+			if seen[j] || !r.moves[removeComb[i]][s] {
+				continue
+			}
+			seen[j] = true
+			if placedBy[j] < 0 || augment(placedBy[j], seen) {
+				placedBy[j] = i
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := range removeComb {
+		if !augment(i, make([]bool, len(placeComb))) {
+			return nil
+		}
+	}
+	return placedBy
+}
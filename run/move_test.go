@@ -0,0 +1,90 @@
+package run
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+// testing level with three sides of a square and a match lying next to it on the top line,
+// it is solved by pivoting the loose match down, but not by sliding it
+func strayMatchLevel(bit bool, model MoveModel) *Level {
+	matches := []*field.MatchPosition{
+		{X: 0, Y: 0, S: field.Top},
+		{X: 0, Y: 0, S: field.Bot},
+		{X: 0, Y: 0, S: field.Lft},
+		{X: 1, Y: 0, S: field.Top},
+	}
+
+	lvl := returnLevel(bit, moveGame, 1, 1, 4, 4, matches)
+	lvl.MoveModel = model
+	return lvl
+}
+
+func TestMoveModels(t *testing.T) {
+	for _, bit := range []bool{false, true} {
+		assert.Len(t, NewRun(strayMatchLevel(bit, nil)).SolveGame(Options{}), 1)
+		assert.Len(t, NewRun(strayMatchLevel(bit, FreeMove)).SolveGame(Options{}), 1)
+		assert.Len(t, NewRun(strayMatchLevel(bit, PivotMove)).SolveGame(Options{}), 1)
+		assert.Empty(t, NewRun(strayMatchLevel(bit, SlideMove)).SolveGame(Options{}))
+	}
+}
+
+func TestChainedMove(t *testing.T) {
+	// a can pivot into the place of b, and b can then pivot onto c, but a cannot pivot onto c itself
+	a, b, c := field.Edge{X: 0, Y: 0}, field.Edge{X: 1, Y: 0, Vertical: true}, field.Edge{X: 1, Y: 1}
+	assert.True(t, PivotMove.CanMove(a, b))
+	assert.True(t, PivotMove.CanMove(b, c))
+	assert.False(t, PivotMove.CanMove(a, c))
+	matches := []*field.MatchPosition{a.Position(4, 4), b.Position(4, 4)}
+	for _, bit := range []bool{false, true} {
+		for _, model := range []MoveModel{FreeMove, PivotMove} {
+			lvl := returnLevel(bit, moveGame, 1, 0, 4, 4, matches)
+			lvl.MoveModel = model
+			runner := NewRun(lvl)
+
+			index := func(state field.State, e field.Edge) []int {
+				for i, edge := range runner.field.GetEdges(state) {
+					if edge == e {
+						return []int{i}
+					}
+				}
+				panic("no such edge")
+			}
+			// only one match moves, from a onto c
+			assert.Equal(t, model == FreeMove, runner.canMove(index(field.Match, a), index(field.Space, c)))
+		}
+	}
+}
+
+func TestPivotMove(t *testing.T) {
+	h := field.Edge{X: 1, Y: 1}
+	assert.True(t, PivotMove.CanMove(h, field.Edge{X: 1, Y: 0, Vertical: true}))
+	assert.True(t, PivotMove.CanMove(h, field.Edge{X: 1, Y: 1, Vertical: true}))
+	assert.True(t, PivotMove.CanMove(h, field.Edge{X: 2, Y: 0, Vertical: true}))
+	assert.True(t, PivotMove.CanMove(h, field.Edge{X: 2, Y: 1, Vertical: true}))
+	assert.False(t, PivotMove.CanMove(h, field.Edge{X: 0, Y: 1, Vertical: true}))
+	assert.False(t, PivotMove.CanMove(h, field.Edge{X: 1, Y: 2, Vertical: true}))
+	assert.False(t, PivotMove.CanMove(h, field.Edge{X: 2, Y: 1}))
+
+	v := field.Edge{X: 1, Y: 1, Vertical: true}
+	assert.True(t, PivotMove.CanMove(v, field.Edge{X: 0, Y: 1}))
+	assert.True(t, PivotMove.CanMove(v, field.Edge{X: 1, Y: 2}))
+	assert.False(t, PivotMove.CanMove(v, field.Edge{X: 1, Y: 0}))
+}
+
+func TestSlideMove(t *testing.T) {
+	h := field.Edge{X: 1, Y: 1}
+	assert.True(t, SlideMove.CanMove(h, field.Edge{X: 0, Y: 1}))
+	assert.True(t, SlideMove.CanMove(h, field.Edge{X: 2, Y: 1}))
+	assert.False(t, SlideMove.CanMove(h, field.Edge{X: 3, Y: 1}))
+	assert.False(t, SlideMove.CanMove(h, field.Edge{X: 1, Y: 2}))
+	assert.False(t, SlideMove.CanMove(h, field.Edge{X: 1, Y: 1, Vertical: true}))
+
+	v := field.Edge{X: 1, Y: 1, Vertical: true}
+	assert.True(t, SlideMove.CanMove(v, field.Edge{X: 1, Y: 0, Vertical: true}))
+	assert.True(t, SlideMove.CanMove(v, field.Edge{X: 1, Y: 2, Vertical: true}))
+	assert.False(t, SlideMove.CanMove(v, field.Edge{X: 2, Y: 1, Vertical: true}))
+}
@@ -0,0 +1,87 @@
+package run
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+)
+
+// Objective is the goal of an optimisation.
+type Objective int
+
+//noinspection GoExportedElementShouldHaveComment
+const (
+	Maximise Objective = iota
+	Minimise
+)
+
+// score returns a score for a number of squares, a higher score is better.
+func (o Objective) score(squares int) int64 {
+	if o == Minimise {
+		return -int64(squares)
+	}
+	return int64(squares)
+}
+
+// Optimise searches the same layouts as SolveGame, ignoring the Target,
+// and returns the most or least number of squares found with every layout that has that number.
+// Only layouts where every match is part of a square are considered.
+// It returns -1 and an empty slice if there are no such layouts.
+// It uses the workers and the range of removals of opts.
+// It stops early once ctx is done, returning the error of ctx, or the error of a worker that failed.
+func (r *Run) Optimise(ctx context.Context, objective Objective, opts Options) (int, []FieldI, error) {
+	if r.gameType != removeGame && r.gameType != moveGame {
+		panic("Unknown Game Type")
+	}
+
+	// the best score seen by any worker, layouts with a worse score are not sent
+	bestScore := int64(math.MinInt64)
+
+	// this task sends every layout that is at least as good as the best score so far
+	newTask := func() Task[*taskParams, *taskReturn] {
+		f := r.field.Copy(false).(FieldI)
+		return func(ctx context.Context, tp *taskParams, output func(*taskReturn) bool) error {
+			r.eachLayout(ctx, f, tp, func([]int, []int) {
+				count, covered := f.CountSquares()
+				if !covered {
+					return
+				}
+				score := objective.score(count)
+				for {
+					seen := atomic.LoadInt64(&bestScore)
+					if score < seen {
+						return
+					}
+					if score == seen || atomic.CompareAndSwapInt64(&bestScore, seen, score) {
+						break
+					}
+				}
+				output(&taskReturn{
+					f:       f.Copy(true).(FieldI),
+					squares: count,
+				})
+			})
+			return nil
+		}
+	}
+	start, end := opts.removals(r.removeCombsTotal)
+	pool := NewPool(ctx, opts.Pool, newTask)
+	go r.sendBatches(pool, start, end, opts.Pool.batch(), newProgress(start))
+
+	best := -1
+	layouts := make([]FieldI, 0)
+	for result := range pool.Outputs() {
+		if best < 0 || objective.score(result.squares) > objective.score(best) {
+			best = result.squares
+			layouts = layouts[:0]
+		}
+		if result.squares == best {
+			layouts = append(layouts, result.f)
+		}
+	}
+	if err := pool.Err(); err != nil {
+		return best, layouts, err
+	}
+
+	return best, layouts, ctx.Err()
+}
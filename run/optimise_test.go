@@ -0,0 +1,32 @@
+package run
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimise(t *testing.T) {
+	for _, bit := range []bool{false, true} {
+		best, layouts, err := NewRun(Lvl6(bit)).Optimise(context.Background(), Maximise, Options{})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, best)
+		assert.Len(t, layouts, 1)
+
+		best, layouts, err = NewRun(Lvl6(bit)).Optimise(context.Background(), Minimise, Options{})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, best)
+		assert.Len(t, layouts, 4)
+
+		best, layouts, err = NewRun(multipleSolutionsLevel(bit)).Optimise(context.Background(), Maximise, Options{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, best)
+		assert.Len(t, layouts, 13)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := NewRun(Lvl6(true)).Optimise(ctx, Maximise, Options{})
+	assert.Equal(t, context.Canceled, err)
+}
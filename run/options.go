@@ -0,0 +1,56 @@
+package run
+
+import "time"
+
+// Options change how a Run is solved.
+type Options struct {
+	// MaxSolutions is the most solutions that are returned, the first ones found unless Deterministic is set.
+	// Every solution is returned if it is 0.
+	MaxSolutions int
+	// Deterministic returns the solutions ordered by the combination index of their removal and then of their
+	// placement, instead of in the order that they are found, so with MaxSolutions the first ones in that order.
+	// Solutions are then held back until every solution before them was found. The other solvers, like SolveDLX,
+	// ignore it.
+	Deterministic bool
+	// Start and End limit the removals that are tried to the ones with a combination index in [Start, End),
+	// so that a search can be split into shards, see Run.Shard. An End of 0 is the number of removals,
+	// unless Bounded is set.
+	Start int
+	End   int
+	// Bounded makes an End of 0 an empty range instead of every removal, as a shard can be empty.
+	Bounded bool
+	// Checkpoint is a file that the progress of the search is saved to every CheckpointInterval and once it stops,
+	// so that it can be resumed. No checkpoints are saved if it is empty.
+	Checkpoint string
+	// CheckpointInterval is the time between checkpoints, a minute if it is 0.
+	CheckpointInterval time.Duration
+	// Resume continues the search from the Checkpoint file if it exists, instead of starting over.
+	Resume bool
+	// Pool configures the workers of the search.
+	Pool PoolConfig
+	// OnProgress is called with the Progress of the search every ProgressInterval and once it stops,
+	// from the goroutine that started the search. Nothing is reported if it is nil.
+	OnProgress func(Progress)
+	// ProgressInterval is the least time between calls of OnProgress, a second if it is 0.
+	ProgressInterval time.Duration
+}
+
+// removals returns the range of removal combination indices to try, out of total removals.
+func (o Options) removals(total int) (start, end int) {
+	start, end = o.Start, o.End
+	if (end <= 0 && !o.Bounded) || end > total {
+		end = total
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// enough returns true if the number of solutions found is as many as the Options allow.
+func (o Options) enough(found int) bool {
+	return o.MaxSolutions > 0 && found >= o.MaxSolutions
+}
@@ -0,0 +1,79 @@
+package run
+
+import (
+	"sort"
+
+	"gonum.org/v1/gonum/stat/combin"
+)
+
+type (
+	// ordered passes solutions on to fn. If it holds them back, it does so until every solution before them
+	// was found, so that they are passed on ordered by the combination index of their removal
+	// and then of their placement, no matter in which order the workers find them.
+	ordered struct {
+		r       *Run
+		fn      func(*taskReturn) bool
+		hold    bool
+		results []orderedResult
+	}
+
+	orderedResult struct {
+		result          *taskReturn
+		removeCombIndex int
+		placeCombIndex  int
+	}
+)
+
+func (r *Run) newOrdered(fn func(*taskReturn) bool, hold bool) *ordered {
+	return &ordered{
+		r:       r,
+		fn:      fn,
+		hold:    hold,
+		results: make([]orderedResult, 0),
+	}
+}
+
+// add passes a solution on, or holds it back until flush, and returns false if fn did.
+func (o *ordered) add(result *taskReturn) bool {
+	if !o.hold {
+		return o.fn(result)
+	}
+	placeCombIndex := 0
+	if o.r.gameType == moveGame {
+		placeCombIndex = combin.CombinationIndex(result.placeComb, o.r.spaceCount, o.r.movable)
+	}
+	o.results = append(o.results, orderedResult{
+		result:          result,
+		removeCombIndex: combin.CombinationIndex(result.removeComb, o.r.matchCount, o.r.movable),
+		placeCombIndex:  placeCombIndex,
+	})
+	return true
+}
+
+// flush passes on the solutions that were held back in order, once every removal before done is done,
+// and returns false as soon as fn does.
+func (o *ordered) flush(done int) bool {
+	if len(o.results) == 0 {
+		return true
+	}
+	sort.Slice(o.results, func(i, j int) bool {
+		a, b := o.results[i], o.results[j]
+		if a.removeCombIndex != b.removeCombIndex {
+			return a.removeCombIndex < b.removeCombIndex
+		}
+		return a.placeCombIndex < b.placeCombIndex
+	})
+
+	n := 0
+	for n < len(o.results) && o.results[n].removeCombIndex < done {
+		n++
+	}
+	ready := o.results[:n]
+	o.results = o.results[n:]
+	for _, r := range ready {
+		if !o.fn(r.result) {
+			return false
+		}
+	}
+	return true
+}
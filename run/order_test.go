@@ -0,0 +1,71 @@
+package run
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/stat/combin"
+)
+
+// solveResults returns the results of solving a Run, in the order that they are passed on.
+func solveResults(t *testing.T, r *Run, opts Options) []*taskReturn {
+	results := make([]*taskReturn, 0)
+	fn := func(result *taskReturn) bool {
+		results = append(results, result)
+		return !opts.enough(len(results))
+	}
+	if r.gameType == removeGame {
+		assert.NoError(t, r.removeGame(context.Background(), opts, fn))
+	} else {
+		assert.NoError(t, r.moveGame(context.Background(), opts, true, fn))
+	}
+	return results
+}
+
+func TestDeterministic(t *testing.T) {
+	levels := []func(bool) *Level{Lvl6, multipleSolutionsLevel, blockLevel, Lvl16Test}
+	for _, newLevel := range levels {
+		for _, bit := range []bool{false, true} {
+			runner := NewRun(newLevel(bit))
+			expected := runner.SolveGame(Options{})
+			results := solveResults(t, runner, Options{Deterministic: true})
+
+			fields := make([]FieldI, len(results))
+			for i, result := range results {
+				fields[i] = result.f
+			}
+			assert.ElementsMatch(t, layouts(expected), layouts(fields))
+
+			// the results are ordered by their removal and then placement
+			for i := 1; i < len(results); i++ {
+				a, b := results[i-1], results[i]
+				aRemove := combin.CombinationIndex(a.removeComb, runner.matchCount, runner.movable)
+				bRemove := combin.CombinationIndex(b.removeComb, runner.matchCount, runner.movable)
+				assert.LessOrEqual(t, aRemove, bRemove)
+				if aRemove == bRemove {
+					assert.Less(t, combin.CombinationIndex(a.placeComb, runner.spaceCount, runner.movable),
+						combin.CombinationIndex(b.placeComb, runner.spaceCount, runner.movable))
+				}
+			}
+
+			// the first solutions in that order are returned
+			for _, max := range []int{1, 2} {
+				if max > len(fields) {
+					continue
+				}
+				solutions := NewRun(newLevel(bit)).SolveGame(Options{MaxSolutions: max, Deterministic: true})
+				assert.Equal(t, layouts(fields[:max]), layouts(solutions))
+			}
+
+			// the images of the solutions of a shard can have removals in other shards
+			sharded := make([]FieldI, 0)
+			for i := 0; i < 4; i++ {
+				start, end := runner.Shard(i, 4)
+				solutions := NewRun(newLevel(bit)).SolveGame(Options{Deterministic: true, Start: start, End: end, Bounded: true})
+				sharded = append(sharded, solutions...)
+			}
+			assert.ElementsMatch(t, layouts(expected), layouts(sharded))
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package run
+
+import (
+	"sync"
+	"time"
+)
+
+// Progress is how far a search got, as it is reported to Options.OnProgress.
+type Progress struct {
+	Processed int           // the combinations that were tried or pruned so far
+	Total     int           // the combinations in the range of removals of the search
+	Solutions int           // the solutions found so far
+	Elapsed   time.Duration // the time since the search started
+	Rate      float64       // a moving average of the combinations processed per second
+	ETA       time.Duration // the time that the remaining combinations take at Rate
+}
+
+const (
+	// defaultProgressInterval is the time between reports if Options.ProgressInterval is not set.
+	defaultProgressInterval = time.Second
+	// rateSmoothing is the weight of the rate since the last report in the moving average of Progress.Rate.
+	rateSmoothing = 0.3
+)
+
+type (
+	// reporter reports the Progress of a search to the OnProgress of its Options, at most once every interval.
+	reporter struct {
+		fn         func(Progress)
+		interval   time.Duration
+		perRemoval int // the combinations of a removal
+		progress   Progress
+		started    time.Time
+		reported   time.Time
+	}
+
+	// progress keeps track of the removals that are done, while the workers finish them out of order.
+	progress struct {
+		sync.Mutex
+		next    int         // every removal before next was sent to the workers
+		pending map[int]int // the end of the tasks that were sent to the workers but are not done yet, by their start
+	}
+)
+
+// newReporter returns a reporter for a search of the removals [start, end) of opts,
+// where every removal before done is done already.
+func (r *Run) newReporter(opts Options, start, end, done int) *reporter {
+	rp := &reporter{
+		fn:         opts.OnProgress,
+		interval:   opts.ProgressInterval,
+		perRemoval: 1,
+		started:    time.Now(),
+	}
+	if rp.interval <= 0 {
+		rp.interval = defaultProgressInterval
+	}
+	if r.gameType == moveGame {
+		rp.perRemoval = r.placeCombsTotal
+	}
+	rp.reported = rp.started
+	rp.progress.Total = (end - start) * rp.perRemoval
+	rp.progress.Processed = (done - start) * rp.perRemoval
+	return rp
+}
+
+// update reports that processed removals are done and solutions were found, if the interval passed since the last one.
+func (rp *reporter) update(processed, solutions int) {
+	if rp.fn == nil || time.Since(rp.reported) < rp.interval {
+		return
+	}
+	rp.report(processed, solutions)
+}
+
+// finish reports that processed removals are done and solutions were found once the search stopped.
+func (rp *reporter) finish(processed, solutions int) {
+	if rp.fn == nil {
+		return
+	}
+	rp.report(processed, solutions)
+}
+
+func (rp *reporter) report(processed, solutions int) {
+	now := time.Now()
+	p := &rp.progress
+	since := now.Sub(rp.reported).Seconds()
+	if since > 0 {
+		rate := float64(processed*rp.perRemoval-p.Processed) / since
+		if p.Rate == 0 {
+			p.Rate = rate
+		} else {
+			p.Rate = rateSmoothing*rate + (1-rateSmoothing)*p.Rate
+		}
+	}
+	p.Processed = processed * rp.perRemoval
+	p.Solutions = solutions
+	p.Elapsed = now.Sub(rp.started)
+	p.ETA = 0
+	if p.Rate > 0 {
+		p.ETA = time.Duration(float64(p.Total-p.Processed) / p.Rate * float64(time.Second))
+	}
+	rp.reported = now
+	rp.fn(*p)
+}
+
+func newProgress(start int) *progress {
+	return &progress{
+		next:    start,
+		pending: make(map[int]int),
+	}
+}
+
+// send marks the removals with a combination index in [start, end) as sent to the workers in one task.
+func (p *progress) send(start, end int) {
+	p.Lock()
+	defer p.Unlock()
+	p.pending[start] = end
+	p.next = end
+}
+
+// done marks the task of the removals starting at index as done and returns its number of removals.
+func (p *progress) done(index int) int {
+	p.Lock()
+	defer p.Unlock()
+	end := p.pending[index]
+	delete(p.pending, index)
+	return end - index
+}
+
+// watermark returns the combination index below which every removal is done.
+func (p *progress) watermark() int {
+	p.Lock()
+	defer p.Unlock()
+	w := p.next
+	for index := range p.pending {
+		if index < w {
+			w = index
+		}
+	}
+	return w
+}
@@ -0,0 +1,57 @@
+package run
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	p := newProgress(3)
+	assert.Equal(t, 3, p.watermark())
+	p.send(3, 5)
+	p.send(5, 8)
+	assert.Equal(t, 3, p.watermark())
+	assert.Equal(t, 3, p.done(5))
+	assert.Equal(t, 3, p.watermark())
+	assert.Equal(t, 2, p.done(3))
+	assert.Equal(t, 8, p.watermark())
+}
+
+func TestOnProgress(t *testing.T) {
+	for _, newLevel := range []func(bool) *Level{Lvl6, Lvl16Test} {
+		runner := NewRun(newLevel(true))
+		reports := make([]Progress, 0)
+		solutions, err := runner.SolveGameContext(context.Background(), Options{
+			OnProgress:       func(p Progress) { reports = append(reports, p) },
+			ProgressInterval: time.Nanosecond,
+		})
+		assert.NoError(t, err)
+
+		assert.Greater(t, len(reports), 1)
+		for i := 1; i < len(reports); i++ {
+			assert.LessOrEqual(t, reports[i-1].Processed, reports[i].Processed)
+			assert.LessOrEqual(t, reports[i-1].Solutions, reports[i].Solutions)
+			assert.Equal(t, runner.totalCombinations, reports[i].Total)
+		}
+		last := reports[len(reports)-1]
+		assert.Equal(t, last.Total, last.Processed)
+		assert.Equal(t, len(solutions), last.Solutions)
+		assert.Greater(t, last.Rate, 0.0)
+		assert.Equal(t, time.Duration(0), last.ETA)
+	}
+
+	// a range of removals only counts the combinations in the range
+	runner := NewRun(Lvl16Test(true))
+	var last Progress
+	_, err := runner.SolveGameContext(context.Background(), Options{
+		Start:      10,
+		End:        30,
+		OnProgress: func(p Progress) { last = p },
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 20*runner.placeCombsTotal, last.Total)
+	assert.Equal(t, last.Total, last.Processed)
+}
@@ -2,8 +2,10 @@
 package run
 
 import (
+	"context"
 	"fmt"
-	"time"
+	"math"
+	"sort"
 
 	"golang.org/x/text/language"
 	"gonum.org/v1/gonum/stat/combin"
@@ -21,8 +23,14 @@ type (
 		display.FieldI
 		GetSpacesCount() int
 		GetMatchesCount() int
+		GetEdges(state field.State) []field.Edge
 		ChangeToState(list []int, fromState field.State, toState field.State)
+		ChangeOne(i int, fromState field.State, toState field.State)
+		GetSquaresCount() int
+		GetCoveredCount() int
 		CheckSquares(requiredShapes int) bool
+		CountSquares() (int, bool)
+		Bound(placeable int) (int, bool)
 		Copy(bool) field.Copyable
 	}
 	// Run is a collection of information needed to find a solution to a Level
@@ -35,8 +43,10 @@ type (
 		removeCombsTotal  int
 		placeCombsTotal   int
 		totalCombinations int
-		shapesRequired    int
+		target            *Target
 		gameType          gameType
+		moves             [][]bool    // which matches can be moved onto which spaces, nil if any
+		symmetries        []*symmetry // symmetries of the initial layout, other than the identity
 		printer           *io.Printer
 	}
 )
@@ -47,6 +57,10 @@ func NewRun(lvl *Level) *Run {
 	sCount := lvl.Field.GetSpacesCount()
 	movable := lvl.Movable
 	removeCombs := combin.Binomial(mCount, movable)
+	target := lvl.Target
+	if target == nil {
+		target = Exactly(lvl.ShapesRequired)
+	}
 	var placeCombs int
 	var totalCombinations int
 	switch lvl.GameType {
@@ -65,9 +79,11 @@ func NewRun(lvl *Level) *Run {
 		movable:           movable,
 		removeCombsTotal:  removeCombs,
 		placeCombsTotal:   placeCombs,
-		shapesRequired:    lvl.ShapesRequired,
+		target:            target,
 		gameType:          lvl.GameType,
 		totalCombinations: totalCombinations,
+		moves:             moveTable(lvl.Field, lvl.MoveModel),
+		symmetries:        fieldSymmetries(lvl.Field, lvl.MoveModel),
 		printer:           io.NewPrinter(language.English),
 	}
 }
@@ -88,129 +104,346 @@ func (r *Run) PrintStats() {
 
 // SolveGame runs the Run and returns solutions.
 // It returns a slice of fields in the solved state (empty slice if no solutions).
-// If oneSolution is set, SolveGame will return only the first solution that it finds.
-func (r *Run) SolveGame(oneSolution bool) []FieldI {
+func (r *Run) SolveGame(opts Options) []FieldI {
+	solutions, _ := r.SolveGameContext(context.Background(), opts)
+	return solutions
+}
+
+// SolveGameContext is SolveGame that stops early once ctx is done.
+// It then returns the solutions found so far together with the error of ctx.
+// Every goroutine that it started has stopped by the time it returns.
+func (r *Run) SolveGameContext(ctx context.Context, opts Options) ([]FieldI, error) {
 	switch r.gameType {
 	case removeGame:
-		return r.RemoveGame(oneSolution)
+		return r.RemoveGameContext(ctx, opts)
 	case moveGame:
-		return r.MoveGame(oneSolution)
+		return r.MoveGameContext(ctx, opts)
 	default:
 		panic("Unknown Game Type")
 	}
 }
 
+// collect returns the fields of the results that stream sends, stopping once there are opts.MaxSolutions of them.
+func collect(opts Options, stream func(fn func(*taskReturn) bool) error) ([]FieldI, error) {
+	solutions := make([]FieldI, 0)
+	err := stream(func(result *taskReturn) bool {
+		solutions = append(solutions, result.f)
+		return !opts.enough(len(solutions))
+	})
+	return solutions, err
+}
+
 // RemoveGame runs the Run as the remove game type and returns solutions.
 // It returns a slice of fields in the solved state (empty slice if no solutions).
-// If oneSolution is set, SolveGame will return only the first solution that it finds.
-func (r *Run) RemoveGame(oneSolution bool) []FieldI {
-	solutions := make([]FieldI, 0)
-	removable := r.movable
-	removeComb := make([]int, removable)
+// Only removals that are canonical under the symmetries of the field are tried,
+// the other solutions are found by applying the symmetries to the solutions of those.
+func (r *Run) RemoveGame(opts Options) []FieldI {
+	solutions, _ := r.RemoveGameContext(context.Background(), opts)
+	return solutions
+}
 
-	// init removeComb to [0, 1 , 2 ... r.movable-1]
-	for i := 0; i < r.movable; i++ {
-		removeComb[i] = i
-	}
-	removeCombIndex := combin.CombinationIndex(removeComb, r.matchCount, r.movable)
+// RemoveGameContext is RemoveGame that stops early once ctx is done,
+// returning the solutions found so far together with the error of ctx.
+func (r *Run) RemoveGameContext(ctx context.Context, opts Options) ([]FieldI, error) {
+	return collect(opts, func(fn func(*taskReturn) bool) error {
+		return r.removeGame(ctx, opts, fn)
+	})
+}
 
-	for removeCombIndex < r.removeCombsTotal {
-		// remove the matches that we guess we need to remove
-		r.field.ChangeToState(removeComb, field.Match, field.Space)
+// removeGame calls fn with every solution of the remove game in the range of removals of opts,
+// until fn returns false or ctx is done. It saves checkpoints and resumes from them as opts asks.
+func (r *Run) removeGame(ctx context.Context, opts Options, fn func(*taskReturn) bool) error {
+	return r.search(ctx, opts, fn, r.removeTasks())
+}
 
-		// check if solving combination found
-		if r.field.CheckSquares(r.shapesRequired) {
-			solution := r.field.Copy(true).(FieldI)
-			solutions = append(solutions, solution)
-			if oneSolution {
-				return solutions
-			}
+// removeTasks returns the function that creates the task of every worker of the remove game,
+// which removes the matches of its tasks from its own copy of the field and sends any solutions it finds.
+func (r *Run) removeTasks() func() Task[*taskParams, *taskReturn] {
+	return func() Task[*taskParams, *taskReturn] {
+		f := r.field.Copy(false).(FieldI)
+		return func(ctx context.Context, tp *taskParams, output func(*taskReturn) bool) error {
+			r.eachRemoval(ctx, f, tp, true, func(removeComb []int) {
+				if r.isSolution(f) {
+					output(&taskReturn{
+						f:          f.Copy(true).(FieldI),
+						removeComb: append([]int(nil), removeComb...),
+					})
+				}
+			})
+			return nil
 		}
-
-		// put the matches we removed back
-		r.field.ChangeToState(removeComb, field.Match, field.Match)
-
-		ec.NextCombination(removeComb, r.matchCount, r.movable)
-		removeCombIndex++
 	}
-
-	return solutions
 }
 
 // MoveGame runs the Run as the move game type and returns solutions.
 // It returns a slice of fields in the solved state (empty slice if no solutions).
-// If oneSolution is set, SolveGame will return only the first solution that it finds.
-func (r *Run) MoveGame(oneSolution bool) []FieldI {
-	removeComb := make([]int, r.movable)
-	// init removeComb to [0, 1 , 2 ... r.movable-1]
-	for i := 0; i < r.movable; i++ {
-		removeComb[i] = i
-	}
-	removeCombIndex := combin.CombinationIndex(removeComb, r.matchCount, r.movable)
-
-	found := make(chan *taskReturn)
-
-	// this task runs through the place combinations and sends any solutions it finds
-	task := func(tp *taskParams) {
-		placeComb := make([]int, r.movable)
-		// init placeComb to [0, 1 , 2 ... r.movable-1]
-		for i := 0; i < r.movable; i++ {
-			placeComb[i] = i
+// Like RemoveGame, it only tries removals that are canonical under the symmetries of the field.
+func (r *Run) MoveGame(opts Options) []FieldI {
+	solutions, _ := r.MoveGameContext(context.Background(), opts)
+	return solutions
+}
+
+// MoveGameContext is MoveGame that stops early once ctx is done, like RemoveGameContext.
+func (r *Run) MoveGameContext(ctx context.Context, opts Options) ([]FieldI, error) {
+	return collect(opts, func(fn func(*taskReturn) bool) error {
+		return r.moveGame(ctx, opts, true, fn)
+	})
+}
+
+// moveGame is removeGame for the move game.
+// If prune is not set, it tries every removal instead of only the ones that are canonical and can reach the target.
+func (r *Run) moveGame(ctx context.Context, opts Options, prune bool, fn func(*taskReturn) bool) error {
+	return r.search(ctx, opts, fn, r.moveTasks(prune))
+}
+
+// moveTasks returns the function that creates the task of every worker of the move game,
+// which runs through the place combinations of every removal of its tasks on its own copy of the field
+// and sends any solutions it finds. If prune is set, removals that cannot reach the target are skipped.
+func (r *Run) moveTasks(prune bool) func() Task[*taskParams, *taskReturn] {
+	var matches, spaces uint64
+	var matchDepositor, spaceDepositor ec.Depositor
+	if f, ok := r.field.(*field.BitField); ok {
+		matches, spaces = f.GetMask(field.Match), f.GetMask(field.Space)
+		matchDepositor, spaceDepositor = ec.NewDepositor(matches), ec.NewDepositor(spaces)
+	}
+
+	return func() Task[*taskParams, *taskReturn] {
+		f := r.field.Copy(false).(FieldI)
+		if f, ok := f.(*field.BitField); ok {
+			return func(ctx context.Context, tp *taskParams, output func(*taskReturn) bool) error {
+				r.eachBitRemoval(ctx, f, matchDepositor, tp, prune, func(layout, removed uint64) {
+					if prune {
+						if squares, coverable := f.BoundOf(layout, r.movable); !coverable || squares < r.target.Min {
+							return
+						}
+					}
+					var removeComb []int
+					r.eachBitSolution(f, layout, spaceDepositor, func(placed uint64) {
+						if removeComb == nil {
+							removeComb = listIndices(matches, removed)
+						}
+						placeComb := listIndices(spaces, placed)
+						if !r.canMove(removeComb, placeComb) {
+							return
+						}
+						solution := f.Copy(true).(*field.BitField)
+						solution.SetLayout(layout | placed)
+						output(&taskReturn{
+							f:          solution,
+							removeComb: removeComb,
+							placeComb:  placeComb,
+						})
+					})
+				})
+				return nil
+			}
 		}
-		placeCombIndex := 0
-		for placeCombIndex < r.placeCombsTotal {
-			// place the matches where we guess they should go
-			tp.f.ChangeToState(placeComb, field.Space, field.Match)
-
-			if tp.f.CheckSquares(r.shapesRequired) {
-				// solving combinations found, send solution
-				found <- &taskReturn{
-					f: tp.f.Copy(true).(FieldI),
+
+		return func(ctx context.Context, tp *taskParams, output func(*taskReturn) bool) error {
+			r.eachRemoval(ctx, f, tp, prune, func(removeComb []int) {
+				if prune && !r.canReach(f) {
+					return
 				}
-			}
+				removeComb = append([]int(nil), removeComb...)
 
-			// remove the matches we placed
-			tp.f.ChangeToState(placeComb, field.Space, field.Space)
-			ec.NextCombination(placeComb, r.spaceCount, r.movable)
-			placeCombIndex++
+				r.eachPlacement(f, func(placeComb []int) {
+					if r.isSolution(f) && r.canMove(removeComb, placeComb) {
+						// solving combinations found, send solution
+						output(&taskReturn{
+							f:          f.Copy(true).(FieldI),
+							removeComb: removeComb,
+							placeComb:  append([]int(nil), placeComb...),
+						})
+					}
+				})
+			})
+			return nil
 		}
 	}
-	workers := Workers(task, found)
+}
 
-	go func() {
-		startTime := time.Now()
-		var checks int64 = 0
-		for removeCombIndex < r.removeCombsTotal {
-			// remove the matches that we guess we need to remove
-			r.field.ChangeToState(removeComb, field.Match, field.Space)
+// search runs the tasks from newTask on a Pool for every batch of removals in the range of opts
+// that is not done yet, and calls fn with every solution that the tasks find and their images under the symmetries
+// of the field, until fn returns false or ctx is done.
+// It saves checkpoints, resumes from them, orders the solutions and reports its progress as opts asks.
+// It returns the error of a task that failed, or the error of ctx if it stopped because of ctx.
+func (r *Run) search(ctx context.Context, opts Options, fn func(*taskReturn) bool,
+	newTask func() Task[*taskParams, *taskReturn]) error {
+
+	initial := r.field.Copy(false).(FieldI)
+	seen := make(map[string]interface{})
+	start, end := opts.removals(r.removeCombsTotal)
+	c, err := r.newCheckpointer(opts, start, end)
+	if err != nil {
+		return err
+	}
+	o := r.newOrdered(fn, opts.Deterministic)
+	if !c.resume(initial, seen, o.add) {
+		return nil
+	}
+	add := c.record(o.add)
 
-			params := taskParams{
-				f:               r.field.Copy(false).(FieldI),
-				removeCombIndex: removeCombIndex,
+	// the Pool is cancelled once fn returns false
+	pool := NewPool(ctx, opts.Pool, func() Task[*taskParams, *taskReturn] {
+		task := newTask()
+		return func(ctx context.Context, tp *taskParams, output func(*taskReturn) bool) error {
+			if err := task(ctx, tp, output); err != nil {
+				return err
 			}
-			workers <- &params
+			// a task that ran while the Pool was cancelled may not have sent all of its solutions
+			if ctx.Err() == nil {
+				output(&taskReturn{removeCombIndex: tp.removeCombIndex, done: true})
+			}
+			return nil
+		}
+	})
+	p := newProgress(c.state.Done)
+	go r.sendBatches(pool, c.state.Done, end, opts.Pool.batch(), p)
+	rp := r.newReporter(opts, start, end, c.state.Done)
+	processed := c.state.Done - start
 
-			if removeCombIndex%100 == 0 {
-				checks++
-				average := time.Duration(int64(time.Now().Sub(startTime)) / checks)
-				_, _ = r.printer.Printf("%d out of %d average: %v\n",
-					removeCombIndex, r.removeCombsTotal, average)
+	stopped := false
+	for result := range pool.Outputs() {
+		if stopped {
+			// keep draining the outputs that were already on their way, until the workers are done.
+			// Their tasks do not count as done, as their solutions are dropped and have to be searched again on resume
+			continue
+		}
+		if result.done {
+			// the solutions of a task are sent before it is done
+			processed += p.done(result.removeCombIndex)
+			rp.update(processed, len(c.state.Solutions))
+			if !o.flush(p.watermark()) {
+				stopped = true
+				pool.Cancel()
+			} else if err = c.update(p.watermark()); err != nil {
+				stopped = true
+				pool.Cancel()
 			}
+			continue
+		}
+		if !r.withImages(initial, result, seen, add) {
+			stopped = true
+			pool.Cancel()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := pool.Err(); err != nil {
+		return err
+	}
+
+	done := p.watermark()
+	if !stopped && done == end {
+		// the images of the solutions can have removals past the end of the range, they are passed on last
+		stopped = !o.flush(math.MaxInt)
+	} else if !stopped {
+		stopped = !o.flush(done)
+	}
+	rp.finish(processed, len(c.state.Solutions))
+	if err := c.save(done); err != nil {
+		return err
+	}
+	if stopped {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// removeCombAt returns the combination of matches to remove with the given combination index,
+// which is the combination that ec.NextCombination reaches after index steps from [0, 1 , 2 ... r.movable-1].
+func (r *Run) removeCombAt(index int) []int {
+	removeComb := combin.IndexToCombination(nil, index, r.matchCount, r.movable)
+	sort.Ints(removeComb)
+	return removeComb
+}
+
+// layout returns a copy of f with the matches in removeComb removed and the spaces in placeComb placed.
+// f must be in its initial state, it is put back in it afterwards.
+func layout(f FieldI, removeComb, placeComb []int) FieldI {
+	f.ChangeToState(removeComb, field.Match, field.Space)
+	f.ChangeToState(placeComb, field.Space, field.Match)
+	l := f.Copy(true).(FieldI)
+	f.ChangeToState(placeComb, field.Space, field.Space)
+	f.ChangeToState(removeComb, field.Match, field.Match)
+	return l
+}
+
+// isSolution returns true if the number of squares on the field is within the target
+// and every match is part of a square.
+func (r *Run) isSolution(f FieldI) bool {
+	if !r.target.Contains(f.GetSquaresCount()) {
+		return false
+	}
+	count, covered := f.CountSquares()
+	return covered && r.target.Contains(count)
+}
+
+// canReach returns false if the field cannot reach the target by placing the movable matches,
+// because too few squares can be completed or a match cannot become part of a square.
+func (r *Run) canReach(f FieldI) bool {
+	squares, coverable := f.Bound(r.movable)
+	return coverable && squares >= r.target.Min
+}
+
+// sendBatches sends the removals with a combination index in [start, end) to a Pool in tasks of batch removals
+// and then closes it. Once the Pool is cancelled it stops sending.
+// It marks every task that it sends in p.
+func (r *Run) sendBatches(pool *Pool[*taskParams, *taskReturn], start, end, batch int, p *progress) {
+	defer pool.Close()
+	for removeCombIndex := start; removeCombIndex < end; removeCombIndex += batch {
+		params := taskParams{
+			removeCombIndex: removeCombIndex,
+			removeCombEnd:   removeCombIndex + batch,
+		}
+		if params.removeCombEnd > end {
+			params.removeCombEnd = end
+		}
+		p.send(params.removeCombIndex, params.removeCombEnd)
+		if !pool.Send(&params) {
+			return
+		}
+	}
+}
+
+// eachRemoval removes every combination of matches of a task from f, calling fn with the removed combination
+// before putting the matches back. Removals of matches that cannot be moved are skipped,
+// and if prune is set, so are removals that are not canonical under the symmetries of the field.
+// It stops once ctx is done.
+func (r *Run) eachRemoval(ctx context.Context, f FieldI, tp *taskParams, prune bool, fn func(removeComb []int)) {
+	removeComb := r.removeCombAt(tp.removeCombIndex)
+	for i := tp.removeCombIndex; i < tp.removeCombEnd && ctx.Err() == nil; i++ {
+		if r.canRemove(removeComb) && (!prune || r.isCanonical(removeComb)) {
+			// remove the matches that we guess we need to remove
+			f.ChangeToState(removeComb, field.Match, field.Space)
+			fn(removeComb)
 			// put the matches we removed back
-			r.field.ChangeToState(removeComb, field.Match, field.Match)
-			ec.NextCombination(removeComb, r.matchCount, r.movable)
-			removeCombIndex++
+			f.ChangeToState(removeComb, field.Match, field.Match)
 		}
-		close(workers)
-	}()
+		ec.NextCombination(removeComb, r.matchCount, r.movable)
+	}
+}
 
-	solutions := make([]FieldI, 0)
-	for result := range found {
-		solutions = append(solutions, result.f)
-		if oneSolution {
+// eachPlacement places every combination of matches on the spaces of f,
+// calling fn with the placed combination before removing them again.
+// Combinations are visited in revolving door order, so that going to the next one
+// moves a single match and only the squares around it have to be updated.
+func (r *Run) eachPlacement(f FieldI, fn func(placeComb []int)) {
+	door := ec.NewRevolvingDoor(r.spaceCount, r.movable)
+	placeComb := door.Combination()
+	// place the matches where we guess they should go
+	f.ChangeToState(placeComb, field.Space, field.Match)
+	for {
+		fn(placeComb)
+
+		out, in, ok := door.Next()
+		if !ok {
 			break
 		}
+		f.ChangeOne(out, field.Space, field.Space)
+		f.ChangeOne(in, field.Space, field.Match)
 	}
-
-	return solutions
+	// remove the matches we placed
+	f.ChangeToState(placeComb, field.Space, field.Space)
 }
@@ -1,7 +1,9 @@
 package run
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -20,30 +22,30 @@ func multipleSolutionsLevel(bit bool) *Level {
 }
 
 func Test_LvlTestMultiSol(t *testing.T) {
-	doRun(t, multipleSolutionsLevel, true, false)
+	doRun(t, multipleSolutionsLevel, true, Options{})
 }
 
 func Test_Lvl6(t *testing.T) {
-	doRun(t, Lvl6, false, false)
+	doRun(t, Lvl6, false, Options{})
 }
 
 func Test_Lvl6_Bit(t *testing.T) {
-	doRun(t, Lvl6, true, false)
+	doRun(t, Lvl6, true, Options{})
 }
 
 func Test_Lvl16Test(t *testing.T) {
-	doRun(t, Lvl16Test, false, false)
+	doRun(t, Lvl16Test, false, Options{})
 }
 
 func Test_Lvl16Test_Bit(t *testing.T) {
-	doRun(t, Lvl16Test, true, false)
+	doRun(t, Lvl16Test, true, Options{})
 }
 
 func Test_Lvl16Test_Bit_One(t *testing.T) {
-	doRun(t, Lvl16, true, true)
+	doRun(t, Lvl16, true, Options{MaxSolutions: 1})
 }
 
-func doRun(t *testing.T, newLevel func(bool) *Level, bitwise, oneSolution bool) {
+func doRun(t *testing.T, newLevel func(bool) *Level, bitwise bool, opts Options) {
 	logg.Println("Starting Layout:")
 	lvl := newLevel(bitwise)
 	display.Draw(lvl.Field)
@@ -51,7 +53,7 @@ func doRun(t *testing.T, newLevel func(bool) *Level, bitwise, oneSolution bool)
 	runner := NewRun(lvl)
 	runner.PrintStats()
 
-	fs := runner.SolveGame(oneSolution)
+	fs := runner.SolveGame(opts)
 	assert.NotEmpty(t, fs)
 	logg.Println("\n\nSolution:")
 	for _, f := range fs {
@@ -61,11 +63,123 @@ func doRun(t *testing.T, newLevel func(bool) *Level, bitwise, oneSolution bool)
 }
 
 func BenchmarkMoveGame(b *testing.B) {
-	lvl := Lvl16Test(true)
-	runner := NewRun(lvl)
-	runner.PrintStats()
-	for i := 0; i < b.N; i++ {
-		runner.MoveGame(true)
-		fmt.Println(i)
+	pools := map[string]PoolConfig{
+		"Default":  {},
+		"Batch1":   {Batch: 1},
+		"Batch64":  {Batch: 64},
+		"Buffered": {Buffer: 64},
+	}
+	for name, pool := range pools {
+		b.Run(name, func(b *testing.B) {
+			lvl := Lvl16Test(true)
+			runner := NewRun(lvl)
+			runner.PrintStats()
+			for i := 0; i < b.N; i++ {
+				runner.MoveGame(Options{MaxSolutions: 1, Pool: pool})
+				fmt.Println(i)
+			}
+		})
+	}
+}
+
+// solveMoveGame returns every solution of a move game, with or without pruning and symmetry breaking.
+func solveMoveGame(r *Run, prune bool) []FieldI {
+	solutions, _ := collect(Options{}, func(fn func(*taskReturn) bool) error {
+		return r.moveGame(context.Background(), Options{}, prune, fn)
+	})
+	return solutions
+}
+
+func TestMaxSolutions(t *testing.T) {
+	for _, bit := range []bool{false, true} {
+		all := layouts(NewRun(Lvl16Test(bit)).SolveGame(Options{}))
+		assert.Len(t, all, 3)
+		for _, max := range []int{1, 2, 3, 10} {
+			solutions := layouts(NewRun(Lvl16Test(bit)).SolveGame(Options{MaxSolutions: max}))
+			if max > len(all) {
+				max = len(all)
+			}
+			assert.Len(t, solutions, max)
+			assert.Subset(t, all, solutions)
+		}
+	}
+}
+
+func TestSolvers(t *testing.T) {
+	annealing := func(r *Run, opts Options) []FieldI {
+		return r.SolveAnnealing(rand.New(rand.NewSource(1)), Budget{Iterations: 100000}, opts)
+	}
+	solvers := []struct {
+		name  string
+		solve func(*Run, Options) []FieldI
+		// complete solvers find every solution, the others only some of them
+		complete bool
+	}{
+		{"DLX", (*Run).SolveDLX, true},
+		{"SAT", (*Run).SolveSAT, true},
+		{"Squares", (*Run).SolveSquares, true},
+		{"MeetInTheMiddle", (*Run).SolveMeetInTheMiddle, true},
+		{"Annealing", annealing, false},
+	}
+	levels := []*Level{
+		Lvl6(false),
+		Lvl6(true),
+		multipleSolutionsLevel(false),
+		multipleSolutionsLevel(true),
+		blockLevel(true),
+		strayMatchLevel(true, PivotMove),
+		strayMatchLevel(true, SlideMove),
+		Lvl16Test(true),
+	}
+
+	for _, solver := range solvers {
+		t.Run(solver.name, func(t *testing.T) {
+			for _, lvl := range levels {
+				expected := layouts(NewRun(lvl).SolveGame(Options{}))
+				solutions := layouts(solver.solve(NewRun(lvl), Options{}))
+				if solver.complete {
+					assert.ElementsMatch(t, expected, solutions)
+				} else {
+					assert.Equal(t, len(expected) > 0, len(solutions) > 0)
+					assert.Subset(t, expected, solutions)
+				}
+
+				for _, max := range []int{1, 2} {
+					solutions := solver.solve(NewRun(lvl), Options{MaxSolutions: max})
+					if solver.complete && max <= len(expected) {
+						assert.Len(t, solutions, max)
+					}
+					assert.LessOrEqual(t, len(solutions), max)
+				}
+			}
+		})
+	}
+}
+
+func Test_Prune(t *testing.T) {
+	for _, lvl := range []*Level{multipleSolutionsLevel(false), multipleSolutionsLevel(true), Lvl16Test(true)} {
+		pruned := solveMoveGame(NewRun(lvl), true)
+		all := solveMoveGame(NewRun(lvl), false)
+		assert.NotEmpty(t, pruned)
+		assert.ElementsMatch(t, layouts(all), layouts(pruned))
+	}
+}
+
+// layouts returns the layouts of fields as strings of the state of every edge, used for comparing solutions
+func layouts(fs []FieldI) []string {
+	ls := make([]string, len(fs))
+	for i, f := range fs {
+		w, h := f.GetWidth(), f.GetHeight()
+		l := make([]byte, 0)
+		for _, e := range field.Edges(w, h) {
+			p := e.Position(w, h)
+			if f.CheckMatch(p.X, p.Y, p.S) == field.Match {
+				l = append(l, '1')
+			} else {
+				l = append(l, '0')
+			}
+		}
+		ls[i] = string(l)
 	}
+	return ls
 }
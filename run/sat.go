@@ -0,0 +1,83 @@
+package run
+
+import (
+	"github.com/rzamm/matchstick-solver/field"
+	"github.com/rzamm/matchstick-solver/sat"
+)
+
+// SolveSAT finds the same solutions as SolveGame, by encoding the Run as a boolean formula
+// and enumerating its models with a SAT solver, instead of trying every combination.
+func (r *Run) SolveSAT(opts Options) []FieldI {
+	w, h := r.field.GetWidth(), r.field.GetHeight()
+	s := sat.NewSolver()
+
+	// a variable for every edge, true if there is a match on it
+	edges := make([]sat.Lit, len(field.Edges(w, h)))
+	for i := range edges {
+		edges[i] = s.NewVar()
+	}
+
+	// exactly movable matches are removed, and as many are placed in a move game
+	matchEdges := r.field.GetEdges(field.Match)
+	spaceEdges := r.field.GetEdges(field.Space)
+	removed := make([]sat.Lit, len(matchEdges))
+	for i, e := range matchEdges {
+		removed[i] = -edges[e.Index(w, h)]
+	}
+	placed := make([]sat.Lit, len(spaceEdges))
+	for i, e := range spaceEdges {
+		placed[i] = edges[e.Index(w, h)]
+	}
+	s.Exactly(removed, r.movable)
+	if r.gameType == moveGame {
+		s.Exactly(placed, r.movable)
+	} else {
+		s.AtMost(placed, 0)
+	}
+
+	// a variable for every square, true if all of its edges have matches
+	squares := field.Squares(w, h)
+	present := make([]sat.Lit, len(squares))
+	squaresOf := make([][]sat.Lit, len(edges)) // the squares that each edge is part of
+	for j, square := range squares {
+		present[j] = s.NewVar()
+		complete := []sat.Lit{present[j]}
+		for _, i := range square {
+			s.AddClause(-present[j], edges[i])
+			complete = append(complete, -edges[i])
+			squaresOf[i] = append(squaresOf[i], present[j])
+		}
+		s.AddClause(complete...)
+	}
+	s.AtLeast(present, r.target.Min)
+	s.AtMost(present, r.target.Max)
+
+	// every match is part of a square
+	for i, e := range edges {
+		s.AddClause(append([]sat.Lit{-e}, squaresOf[i]...)...)
+	}
+
+	solutions := make([]FieldI, 0)
+	s.Models(edges, func() bool {
+		removeComb := make([]int, 0, r.movable)
+		for i, l := range removed {
+			if s.Value(l) {
+				removeComb = append(removeComb, i)
+			}
+		}
+		placeComb := make([]int, 0, r.movable)
+		for i, l := range placed {
+			if s.Value(l) {
+				placeComb = append(placeComb, i)
+			}
+		}
+		if !r.canMove(removeComb, placeComb) {
+			return true
+		}
+
+		solutions = append(solutions, layout(r.field, removeComb, placeComb))
+		return !opts.enough(len(solutions))
+	})
+
+	return solutions
+}
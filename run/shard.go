@@ -0,0 +1,165 @@
+package run
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	stdio "io"
+	"sort"
+	"strings"
+
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+// Shard returns the range of removal combination indices of shard i out of n, counting from 0,
+// to be used as the Start and End of Options with Bounded set, as a shard is empty if n is more than the removals.
+// The shards together cover every removal exactly once.
+func (r *Run) Shard(i, n int) (start, end int) {
+	if n <= 0 || i < 0 || i >= n {
+		panic(fmt.Sprintf("no shard %d of %d", i, n))
+	}
+	return r.removeCombsTotal * i / n, r.removeCombsTotal * (i + 1) / n
+}
+
+// String formats a Move as the Edges it moves between, like "2,3,h>4,1,v",
+// where h is a horizontal and v a vertical Edge. A removal is only the first Edge.
+func (m Move) String() string {
+	if m.To == nil {
+		return formatEdge(m.From)
+	}
+	return formatEdge(m.From) + ">" + formatEdge(*m.To)
+}
+
+func formatEdge(e field.Edge) string {
+	direction := 'h'
+	if e.Vertical {
+		direction = 'v'
+	}
+	return fmt.Sprintf("%d,%d,%c", e.X, e.Y, direction)
+}
+
+func parseEdge(s string) (field.Edge, error) {
+	var e field.Edge
+	var direction rune
+	if _, err := fmt.Sscanf(s, "%d,%d,%c", &e.X, &e.Y, &direction); err != nil {
+		return e, fmt.Errorf("bad edge %q: %v", s, err)
+	}
+	switch direction {
+	case 'h':
+	case 'v':
+		e.Vertical = true
+	default:
+		return e, fmt.Errorf("bad edge %q", s)
+	}
+	return e, nil
+}
+
+// WriteSolutions streams the solutions of the Run to w, one line of moves per solution,
+// and returns the number of solutions written. It stops like Stream.
+func (r *Run) WriteSolutions(ctx context.Context, w stdio.Writer, opts Options) (int, error) {
+	written := 0
+	var writeErr error
+	err := r.Stream(ctx, opts, func(s *Solution) bool {
+		moves := make([]string, len(s.Moves))
+		for i, m := range s.Moves {
+			moves[i] = m.String()
+		}
+		if _, writeErr = fmt.Fprintln(w, strings.Join(moves, " ")); writeErr != nil {
+			return false
+		}
+		written++
+		return true
+	})
+	if writeErr != nil {
+		return written, writeErr
+	}
+	return written, err
+}
+
+// ReadSolutions reads solutions written by WriteSolutions for the same level.
+// The field must be in its initial state, it is used to create the solved fields.
+func (r *Run) ReadSolutions(rd stdio.Reader) ([]*Solution, error) {
+	w, h := r.field.GetWidth(), r.field.GetHeight()
+	// the list index of every line index, matches and spaces both have their own list
+	matchIndex := make(map[int]int)
+	spaceIndex := make(map[int]int)
+	for i, e := range r.field.GetEdges(field.Match) {
+		matchIndex[e.Index(w, h)] = i
+	}
+	for i, e := range r.field.GetEdges(field.Space) {
+		spaceIndex[e.Index(w, h)] = i
+	}
+
+	solutions := make([]*Solution, 0)
+	scanner := bufio.NewScanner(rd)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		s := &Solution{Moves: make([]Move, 0, r.movable)}
+		removeComb := make([]int, 0, r.movable)
+		placeComb := make([]int, 0, r.movable)
+		for _, word := range strings.Fields(scanner.Text()) {
+			edges := strings.Split(word, ">")
+			from, err := parseEdge(edges[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			i, ok := matchIndex[from.Index(w, h)]
+			if !ok {
+				return nil, fmt.Errorf("line %d: no match at %s", line, edges[0])
+			}
+			removeComb = append(removeComb, i)
+			m := Move{From: from}
+
+			if len(edges) > 1 {
+				to, err := parseEdge(edges[1])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %v", line, err)
+				}
+				j, ok := spaceIndex[to.Index(w, h)]
+				if !ok {
+					return nil, fmt.Errorf("line %d: no space at %s", line, edges[1])
+				}
+				placeComb = append(placeComb, j)
+				m.To = &to
+			}
+			s.Moves = append(s.Moves, m)
+		}
+		sort.Ints(removeComb)
+		sort.Ints(placeComb)
+		s.Field = layout(r.field, removeComb, placeComb)
+		solutions = append(solutions, s)
+	}
+	return solutions, scanner.Err()
+}
+
+// MergeSolutions combines solution files written by WriteSolutions, for example by the shards of a level,
+// and writes every solution that is in at least one of them to w once, sorted.
+// It returns the number of solutions written.
+func MergeSolutions(w stdio.Writer, files ...stdio.Reader) (int, error) {
+	seen := make(map[string]interface{})
+	for _, f := range files {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				seen[line] = nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	lines := make([]string, 0, len(seen))
+	for line := range seen {
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return 0, err
+		}
+	}
+	return len(lines), nil
+}
@@ -0,0 +1,69 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	stdio "io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rzamm/matchstick-solver/ec"
+)
+
+func TestRemoveCombAt(t *testing.T) {
+	runner := NewRun(Lvl16Test(true))
+	removeComb := runner.removeCombAt(0)
+	for i := 0; i < runner.removeCombsTotal; i += 7 {
+		assert.Equal(t, removeComb, runner.removeCombAt(i))
+		for j := 0; j < 7; j++ {
+			ec.NextCombination(removeComb, runner.matchCount, runner.movable)
+		}
+	}
+}
+
+func TestShards(t *testing.T) {
+	for _, newLevel := range []func(bool) *Level{Lvl6, multipleSolutionsLevel, blockLevel, Lvl16Test} {
+		expected := NewRun(newLevel(true)).SolveGame(Options{})
+
+		// more shards than removals leaves some of them empty
+		n := 3
+		if total := NewRun(newLevel(true)).removeCombsTotal; total < n {
+			n = total + 2
+		}
+		files := make([]stdio.Reader, n)
+		written := 0
+		for i := range files {
+			runner := NewRun(newLevel(true))
+			start, end := runner.Shard(i, n)
+			file := &bytes.Buffer{}
+			count, err := runner.WriteSolutions(context.Background(), file,
+				Options{Start: start, End: end, Bounded: true})
+			assert.NoError(t, err)
+			if start == end {
+				assert.Zero(t, count)
+			}
+			written += count
+			files[i] = file
+		}
+
+		// the shards do not overlap, so merging them keeps every solution
+		merged := &bytes.Buffer{}
+		count, err := MergeSolutions(merged, files...)
+		assert.NoError(t, err)
+		assert.Equal(t, len(expected), count)
+		assert.Equal(t, len(expected), written)
+
+		// the pointer field reads the same solutions
+		solutions, err := NewRun(newLevel(false)).ReadSolutions(merged)
+		assert.NoError(t, err)
+		fields := make([]FieldI, len(solutions))
+		for i, s := range solutions {
+			fields[i] = s.Field
+		}
+		assert.ElementsMatch(t, layouts(expected), layouts(fields))
+	}
+
+	_, err := NewRun(Lvl6(true)).ReadSolutions(bytes.NewBufferString("0,0,x"))
+	assert.Error(t, err)
+}
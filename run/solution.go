@@ -0,0 +1,63 @@
+package run
+
+import (
+	"context"
+
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+type (
+	// Move takes the match at From and puts it at To.
+	// In a remove game the match is only taken away, and To is nil.
+	Move struct {
+		From field.Edge
+		To   *field.Edge
+	}
+
+	// Solution is a field in a solved state together with the moves that lead to it from the initial layout.
+	Solution struct {
+		Field FieldI
+		Moves []Move
+	}
+)
+
+// Stream solves the Run like SolveGameContext, but calls fn with every solution as soon as it is found
+// instead of collecting them. It stops once fn returns false, opts.MaxSolutions solutions were found or ctx is done,
+// and returns the error of ctx if it stopped because of ctx. fn is called from the goroutine that called Stream.
+func (r *Run) Stream(ctx context.Context, opts Options, fn func(*Solution) bool) error {
+	matches := r.field.GetEdges(field.Match)
+	spaces := r.field.GetEdges(field.Space)
+	found := 0
+	solution := func(result *taskReturn) bool {
+		found++
+		return fn(r.solution(matches, spaces, result)) && !opts.enough(found)
+	}
+
+	switch r.gameType {
+	case removeGame:
+		return r.removeGame(ctx, opts, solution)
+	case moveGame:
+		return r.moveGame(ctx, opts, true, solution)
+	default:
+		panic("Unknown Game Type")
+	}
+}
+
+// solution returns the Solution of a result, where matches and spaces are the Edges of the match and space list.
+func (r *Run) solution(matches, spaces []field.Edge, result *taskReturn) *Solution {
+	moves := make([]Move, len(result.removeComb))
+	if r.gameType == removeGame {
+		for i, m := range result.removeComb {
+			moves[i] = Move{From: matches[m]}
+		}
+		return &Solution{Field: result.f, Moves: moves}
+	}
+
+	// the solution was checked, so the removed matches can be moved onto the placed spaces
+	placedBy := r.assignMoves(result.removeComb, result.placeComb)
+	for j, i := range placedBy {
+		to := spaces[result.placeComb[j]]
+		moves[i] = Move{From: matches[result.removeComb[i]], To: &to}
+	}
+	return &Solution{Field: result.f, Moves: moves}
+}
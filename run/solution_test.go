@@ -0,0 +1,61 @@
+package run
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+func TestStream(t *testing.T) {
+	levels := []*Level{
+		Lvl6(true),
+		multipleSolutionsLevel(false),
+		blockLevel(true),
+		strayMatchLevel(true, PivotMove),
+	}
+	for _, lvl := range levels {
+		expected := NewRun(lvl).SolveGame(Options{})
+		runner := NewRun(lvl)
+		w, h := lvl.Field.GetWidth(), lvl.Field.GetHeight()
+		// every worker solves on its own copy, so the field of the level stays in its initial layout
+		initial := lvl.Field
+
+		solutions := make([]FieldI, 0)
+		err := runner.Stream(context.Background(), Options{}, func(s *Solution) bool {
+			solutions = append(solutions, s.Field)
+
+			// the moves lead from the initial layout to the solution
+			assert.Len(t, s.Moves, lvl.Movable)
+			for _, m := range s.Moves {
+				from := m.From.Position(w, h)
+				assert.Equal(t, field.Match, initial.CheckMatch(from.X, from.Y, from.S))
+				assert.Equal(t, field.Space, s.Field.CheckMatch(from.X, from.Y, from.S))
+				if lvl.GameType == removeGame {
+					assert.Nil(t, m.To)
+					continue
+				}
+				to := m.To.Position(w, h)
+				assert.Equal(t, field.Space, initial.CheckMatch(to.X, to.Y, to.S))
+				assert.Equal(t, field.Match, s.Field.CheckMatch(to.X, to.Y, to.S))
+				if lvl.MoveModel != nil {
+					assert.True(t, lvl.MoveModel.CanMove(m.From, *m.To))
+				}
+			}
+			return true
+		})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, layouts(expected), layouts(solutions))
+
+		// stop after the first solution
+		calls := 0
+		err = runner.Stream(context.Background(), Options{}, func(s *Solution) bool {
+			calls++
+			return false
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+	}
+}
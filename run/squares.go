@@ -0,0 +1,107 @@
+package run
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+// SolveSquares finds the same solutions as SolveGame by starting from the target instead of the initial layout.
+// It tries every set of squares with a number of squares within the target, and accepts the edges of a set
+// if they are the initial matches with the movable matches removed, and as many placed in a move game.
+// Like a BitField, it only works for fields that have at most 64 edges.
+func (r *Run) SolveSquares(opts Options) []FieldI {
+	w, h := r.field.GetWidth(), r.field.GetHeight()
+	edges := len(field.Edges(w, h))
+	if edges > 64 {
+		panic(fmt.Sprintf("cannot fit field with %d spaces into int64", edges))
+	}
+
+	squares := make([]uint64, 0)
+	for _, square := range field.Squares(w, h) {
+		mask := uint64(0)
+		for _, i := range square {
+			mask |= 1 << i
+		}
+		squares = append(squares, mask)
+	}
+
+	// the list index of every edge, and the initial matches
+	listIndex := make([]int, edges)
+	start := uint64(0)
+	for i, e := range r.field.GetEdges(field.Match) {
+		listIndex[e.Index(w, h)] = i
+		start |= 1 << e.Index(w, h)
+	}
+	for i, e := range r.field.GetEdges(field.Space) {
+		listIndex[e.Index(w, h)] = i
+	}
+
+	placeable := 0
+	if r.gameType == moveGame {
+		placeable = r.movable
+	}
+	matches := bits.OnesCount64(start) - r.movable + placeable
+
+	solutions := make([]FieldI, 0)
+	// accept checks the layout of a set of n squares, it returns false to stop searching
+	accept := func(union uint64, n int) bool {
+		if bits.OnesCount64(union) != matches || bits.OnesCount64(union^start) != r.movable+placeable {
+			return true
+		}
+		// the layout may have more squares than the set, it is then found with a bigger set
+		count := 0
+		for _, s := range squares {
+			if union&s == s {
+				count++
+			}
+		}
+		if count != n {
+			return true
+		}
+
+		removeComb := make([]int, 0, r.movable)
+		placeComb := make([]int, 0, placeable)
+		for i := 0; i < edges; i++ {
+			b := uint64(1) << i
+			if start&b != 0 && union&b == 0 {
+				removeComb = append(removeComb, listIndex[i])
+			} else if start&b == 0 && union&b != 0 {
+				placeComb = append(placeComb, listIndex[i])
+			}
+		}
+		if !r.canMove(removeComb, placeComb) {
+			return true
+		}
+		solutions = append(solutions, layout(r.field, removeComb, placeComb))
+		return !opts.enough(len(solutions))
+	}
+
+	// choose adds squares from index from onwards until the set has n squares, it returns false to stop searching
+	// sets with too many matches or too many placed matches are cut off early, their union only grows
+	var choose func(from, left int, union uint64, n int) bool
+	choose = func(from, left int, union uint64, n int) bool {
+		if left == 0 {
+			return accept(union, n)
+		}
+		for j := from; j <= len(squares)-left; j++ {
+			u := union | squares[j]
+			if bits.OnesCount64(u) > matches || bits.OnesCount64(u&^start) > placeable {
+				continue
+			}
+			if !choose(j+1, left-1, u, n) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for n := r.target.Min; n <= r.target.Max && n <= len(squares); n++ {
+		if !choose(0, n, 0, n) {
+			break
+		}
+	}
+
+	return solutions
+}
@@ -0,0 +1,14 @@
+package run
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveSquares(t *testing.T) {
+	// a range of squares needs every number of squares in it
+	lvl := Lvl6(true)
+	lvl.Target = AtLeast(3)
+	assert.ElementsMatch(t, layouts(NewRun(lvl).SolveGame(Options{})), layouts(NewRun(lvl).SolveSquares(Options{})))
+}
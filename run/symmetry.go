@@ -0,0 +1,129 @@
+package run
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+// symmetry is a reflection or rotation that maps the initial layout of a field onto itself,
+// given as permutations of the match list and of the space list.
+type symmetry struct {
+	matches []int
+	spaces  []int
+}
+
+// fieldSymmetries returns the symmetries of the initial layout of f, other than the identity.
+// The built-in move models are symmetric, so a symmetry maps every solution onto another solution.
+// Any other model may not be, so no symmetries are returned for it.
+func fieldSymmetries(f FieldI, model MoveModel) []*symmetry {
+	if model != nil && model != FreeMove && model != PivotMove && model != SlideMove {
+		return nil
+	}
+
+	w, h := f.GetWidth(), f.GetHeight()
+	matchEdges := f.GetEdges(field.Match)
+	spaceEdges := f.GetEdges(field.Space)
+
+	// the list index of every line index, matches and spaces both have their own list
+	listIndex := make([]int, len(field.Edges(w, h)))
+	isMatch := make([]bool, len(listIndex))
+	for i, e := range matchEdges {
+		listIndex[e.Index(w, h)] = i
+		isMatch[e.Index(w, h)] = true
+	}
+	for i, e := range spaceEdges {
+		listIndex[e.Index(w, h)] = i
+	}
+
+	symmetries := make([]*symmetry, 0)
+	for _, perm := range field.Symmetries(w, h)[1:] {
+		s := &symmetry{
+			matches: make([]int, len(matchEdges)),
+			spaces:  make([]int, len(spaceEdges)),
+		}
+		symmetric := true
+		for i, e := range matchEdges {
+			line := perm[e.Index(w, h)]
+			if !isMatch[line] {
+				symmetric = false
+				break
+			}
+			s.matches[i] = listIndex[line]
+		}
+		if !symmetric {
+			continue
+		}
+		// the permutation is a bijection, so the spaces are mapped onto spaces
+		for i, e := range spaceEdges {
+			s.spaces[i] = listIndex[perm[e.Index(w, h)]]
+		}
+		symmetries = append(symmetries, s)
+	}
+	return symmetries
+}
+
+// mapComb maps a combination through a permutation, returning the sorted image.
+func mapComb(perm, comb []int) []int {
+	image := make([]int, len(comb))
+	for i, c := range comb {
+		image[i] = perm[c]
+	}
+	sort.Ints(image)
+	return image
+}
+
+// isCanonical returns true if no symmetry maps removeComb onto a combination that is enumerated before it.
+func (r *Run) isCanonical(removeComb []int) bool {
+	for _, s := range r.symmetries {
+		image := mapComb(s.matches, removeComb)
+		for i := range image {
+			if image[i] < removeComb[i] {
+				return false
+			}
+			if image[i] > removeComb[i] {
+				break
+			}
+		}
+	}
+	return true
+}
+
+// withImages calls fn with a result and then with its images under every symmetry of the field,
+// skipping the ones that were seen before or whose matches cannot be moved, and returns false as soon as fn does.
+// initial is a copy of the field in its initial state, it is used to create the images.
+func (r *Run) withImages(initial FieldI, result *taskReturn, seen map[string]interface{},
+	fn func(*taskReturn) bool) bool {
+
+	key := fmt.Sprint(result.removeComb, result.placeComb)
+	if _, ok := seen[key]; !ok {
+		seen[key] = nil
+		if !fn(result) {
+			return false
+		}
+	}
+
+	for _, s := range r.symmetries {
+		removeComb := mapComb(s.matches, result.removeComb)
+		placeComb := mapComb(s.spaces, result.placeComb)
+		key := fmt.Sprint(removeComb, placeComb)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = nil
+		if !r.canMove(removeComb, placeComb) {
+			continue
+		}
+
+		image := &taskReturn{
+			f:          layout(initial, removeComb, placeComb),
+			removeComb: removeComb,
+			placeComb:  placeComb,
+		}
+		if !fn(image) {
+			return false
+		}
+	}
+	return true
+}
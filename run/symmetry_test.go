@@ -0,0 +1,79 @@
+package run
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rzamm/matchstick-solver/field"
+)
+
+// testing level of a 2x2 block of squares in the middle of the field, it has every symmetry of a square
+func blockLevel(bit bool) *Level {
+	var matches []*field.MatchPosition
+	matches = append(matches, placeSquare(1, 1)...)
+	matches = append(matches, placeSquare(2, 1)...)
+	matches = append(matches, placeSquare(1, 2)...)
+	matches = append(matches, placeSquare(2, 2)...)
+
+	return returnLevel(bit, moveGame, 3, 3, 4, 4, matches)
+}
+
+func TestFieldSymmetries(t *testing.T) {
+	assert.Len(t, NewRun(blockLevel(true)).symmetries, 7)
+	assert.Len(t, NewRun(multipleSolutionsLevel(true)).symmetries, 1)
+	assert.Len(t, NewRun(Lvl6(true)).symmetries, 1)
+	assert.Len(t, NewRun(Lvl16Test(true)).symmetries, 0)
+}
+
+func TestSymmetricMoveGame(t *testing.T) {
+	for _, newLevel := range []func(bool) *Level{blockLevel, multipleSolutionsLevel} {
+		for _, bit := range []bool{false, true} {
+			symmetric := NewRun(newLevel(bit)).MoveGame(Options{})
+			all := solveMoveGame(NewRun(newLevel(bit)), false)
+			assert.NotEmpty(t, symmetric)
+			assert.ElementsMatch(t, layouts(all), layouts(symmetric))
+		}
+	}
+}
+
+// rightMove moves a match to an edge further right only, so it is not symmetric
+type rightMove struct{}
+
+func (rightMove) CanMove(from, to field.Edge) bool {
+	return to.X > from.X
+}
+
+func TestAsymmetricMoveModel(t *testing.T) {
+	for _, bit := range []bool{false, true} {
+		lvl := multipleSolutionsLevel(bit)
+		lvl.MoveModel = rightMove{}
+		runner := NewRun(lvl)
+		assert.Empty(t, runner.symmetries)
+
+		all := solveMoveGame(NewRun(lvl), false)
+		assert.NotEmpty(t, all)
+		assert.ElementsMatch(t, layouts(all), layouts(NewRun(lvl).MoveGame(Options{})))
+
+		// every move of a solution is one that the model allows
+		err := runner.Stream(context.Background(), Options{}, func(s *Solution) bool {
+			for _, m := range s.Moves {
+				assert.NotNil(t, m.To)
+			}
+			return true
+		})
+		assert.NoError(t, err)
+	}
+}
+
+func TestSymmetricRemoveGame(t *testing.T) {
+	for _, bit := range []bool{false, true} {
+		symmetric := NewRun(Lvl6(bit)).RemoveGame(Options{})
+		runner := NewRun(Lvl6(bit))
+		runner.symmetries = nil
+		all := runner.RemoveGame(Options{})
+		assert.NotEmpty(t, symmetric)
+		assert.ElementsMatch(t, layouts(all), layouts(symmetric))
+	}
+}
@@ -0,0 +1,30 @@
+package run
+
+import "math"
+
+// Target is the inclusive range of the number of shapes that a solution may have.
+//noinspection GoUnnecessarilyExportedIdentifiers
+type Target struct {
+	Min int
+	Max int
+}
+
+// Exactly returns a Target of exactly n shapes.
+func Exactly(n int) *Target {
+	return &Target{Min: n, Max: n}
+}
+
+// AtLeast returns a Target of n or more shapes.
+func AtLeast(n int) *Target {
+	return &Target{Min: n, Max: math.MaxInt32}
+}
+
+// AtMost returns a Target of n or less shapes.
+func AtMost(n int) *Target {
+	return &Target{Min: 0, Max: n}
+}
+
+// Contains returns true if n shapes are within the Target.
+func (t *Target) Contains(n int) bool {
+	return t.Min <= n && n <= t.Max
+}
@@ -0,0 +1,61 @@
+package run
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	for _, bit := range []bool{false, true} {
+		// the square can be moved anywhere that does not share a side with it
+		histogram, err := NewRun(multipleSolutionsLevel(bit)).Histogram(context.Background(), Options{})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 13}, histogram)
+	}
+
+	// the workers and range of removals of the options are used
+	expected, err := NewRun(Lvl6(true)).Histogram(context.Background(), Options{})
+	assert.NoError(t, err)
+	runner := NewRun(Lvl6(true))
+	sum := make([]int, 0)
+	for i := 0; i < 3; i++ {
+		start, end := runner.Shard(i, 3)
+		histogram, err := runner.Histogram(context.Background(),
+			Options{Start: start, End: end, Bounded: true, Pool: PoolConfig{Workers: 1, Batch: 1}})
+		assert.NoError(t, err)
+		for count, layouts := range histogram {
+			sum = addToHistogram(sum, count, layouts)
+		}
+	}
+	assert.Equal(t, expected, sum)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewRun(Lvl6(true)).Histogram(ctx, Options{})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestTargetRange(t *testing.T) {
+	for _, bit := range []bool{false, true} {
+		histogram, err := NewRun(Lvl6(bit)).Histogram(context.Background(), Options{})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, histogram)
+
+		solutions := func(target *Target) int {
+			lvl := Lvl6(bit)
+			lvl.Target = target
+			return len(NewRun(lvl).SolveGame(Options{}))
+		}
+		atLeast := 0
+		for n := len(histogram) - 1; n >= 0; n-- {
+			atLeast += histogram[n]
+			assert.Equal(t, histogram[n], solutions(Exactly(n)))
+			assert.Equal(t, atLeast, solutions(AtLeast(n)))
+			assert.Equal(t, atLeast, solutions(&Target{Min: n, Max: len(histogram)}))
+		}
+		assert.Equal(t, histogram[3], solutions(nil))
+		assert.Equal(t, histogram[0]+histogram[1], solutions(AtMost(1)))
+	}
+}
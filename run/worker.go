@@ -1,38 +1,87 @@
 package run
 
 import (
+	"context"
+	"fmt"
 	"runtime"
 	"sync"
 )
 
+// defaultBatch is the number of removals in a task if PoolConfig.Batch is not set.
+const defaultBatch = 16
+
+// PoolConfig configures the workers of a Pool.
+type PoolConfig struct {
+	// Workers is the number of goroutines that run tasks, runtime.NumCPU() if it is 0.
+	Workers int
+	// Batch is the number of removal combination indices that a worker tries in one task, 16 if it is 0.
+	Batch int
+	// Buffer is the number of inputs and outputs that can wait in the channels of the workers, 0 for unbuffered channels.
+	// The outputs that wait when a search stops early are dropped, a checkpoint leaves their removals to the resume.
+	Buffer int
+}
+
+// taskParams is a task of trying the removals with a combination index in [removeCombIndex, removeCombEnd).
 type taskParams struct {
-	f               FieldI
 	removeCombIndex int
+	removeCombEnd   int
 }
 
 type taskReturn struct {
-	f FieldI
+	f          FieldI
+	removeComb []int
+	placeComb  []int
+	squares    int
+	histogram  []int
+	// done is set instead of a solution once the task of the removals starting at removeCombIndex is done
+	removeCombIndex int
+	done            bool
 }
 
-// Workers takes inputs on a channel and runs a task on those inputs.
-// Given a task and an output channel, Workers will return an input channel on which to send inputs.
-// The tasks will be run concurrently, when they are done, the output channel is closed.
-// todo: add a context to kill the goroutines early
-func Workers(task func(*taskParams), output chan *taskReturn) chan *taskParams {
-	// create channels
-	inputs := make(chan *taskParams)
-	goRoutines := runtime.NumCPU()
+type (
+	// Task runs on one input of a Pool, passing its outputs to output, which returns false once ctx is done.
+	// A task should stop soon after ctx is done. An error that it returns stops the Pool.
+	Task[I, O any] func(ctx context.Context, input I, output func(O) bool) error
+
+	// Pool runs tasks on the inputs that are sent to it on a number of goroutines, its workers,
+	// and collects their outputs on a channel. The first error of a task, or a task that panics,
+	// cancels the Pool, so that the remaining inputs are skipped.
+	Pool[I, O any] struct {
+		ctx     context.Context
+		cancel  context.CancelFunc
+		inputs  chan I
+		outputs chan O
+		once    sync.Once
+		err     error
+	}
+)
+
+// NewPool starts the workers of a Pool. Every worker gets its own task from newTask, so a task can keep state,
+// like a copy of the field, that it reuses for all of the inputs of its worker. If newTask panics, the Pool fails
+// like it does when a task panics.
+// The Pool is cancelled once ctx is done.
+func NewPool[I, O any](ctx context.Context, config PoolConfig, newTask func() Task[I, O]) *Pool[I, O] {
+	ctx, cancel := context.WithCancel(ctx)
+	p := &Pool[I, O]{
+		ctx:     ctx,
+		cancel:  cancel,
+		inputs:  make(chan I, config.Buffer),
+		outputs: make(chan O, config.Buffer),
+	}
 
 	// Start launching goroutines
 	// Each goroutine waits for inputs on the input channel
-	// When one arrives, run the task on that input
-	// It's up to the task to return any results to the output channel
+	// When one arrives, run the task on that input, unless the Pool was cancelled
 	wg := sync.WaitGroup{}
+	goRoutines := config.workers()
 	wg.Add(goRoutines)
 	for i := 0; i < goRoutines; i++ {
 		go func() {
-			for input := range inputs {
-				task(input)
+			task := p.newTask(newTask)
+			for input := range p.inputs {
+				if task != nil && ctx.Err() == nil {
+					p.run(task, input)
+				}
 			}
 			wg.Done()
 		}()
@@ -40,8 +89,98 @@ func Workers(task func(*taskParams), output chan *taskReturn) chan *taskParams {
 	// this goroutine waits until all tasks are done, then closes the output channel
 	go func() {
 		wg.Wait()
-		close(output)
+		close(p.outputs)
+		cancel()
 	}()
 
-	return inputs
+	return p
+}
+
+// newTask returns the task of a worker from newTask, or nil if newTask panics, which fails the Pool.
+func (p *Pool[I, O]) newTask(newTask func() Task[I, O]) (task Task[I, O]) {
+	defer func() {
+		if v := recover(); v != nil {
+			p.fail(fmt.Errorf("creating task panicked: %v", v))
+			task = nil
+		}
+	}()
+	return newTask()
+}
+
+// run runs a task on an input, turning a panic into an error.
+func (p *Pool[I, O]) run(task Task[I, O], input I) {
+	defer func() {
+		if v := recover(); v != nil {
+			p.fail(fmt.Errorf("task panicked: %v", v))
+		}
+	}()
+	if err := task(p.ctx, input, p.output); err != nil {
+		p.fail(err)
+	}
+}
+
+// fail cancels the Pool because of err, unless it already failed.
+func (p *Pool[I, O]) fail(err error) {
+	p.once.Do(func() {
+		p.err = err
+		p.cancel()
+	})
+}
+
+// output sends an output of a task, unless the Pool is cancelled first.
+func (p *Pool[I, O]) output(output O) bool {
+	select {
+	case p.outputs <- output:
+		return true
+	case <-p.ctx.Done():
+		return false
+	}
+}
+
+// Send sends an input to the workers. It returns false instead if the Pool is cancelled first.
+func (p *Pool[I, O]) Send(input I) bool {
+	select {
+	case p.inputs <- input:
+		return true
+	case <-p.ctx.Done():
+		return false
+	}
+}
+
+// Close tells the workers that no more inputs are sent, it has to be called even if the Pool is cancelled.
+// Outputs is closed once the workers are done.
+func (p *Pool[I, O]) Close() {
+	close(p.inputs)
+}
+
+// Outputs returns the channel of the outputs of the tasks, which is closed once Close was called
+// and the workers are done. It has to be drained, the workers may wait for it until the Pool is cancelled.
+func (p *Pool[I, O]) Outputs() <-chan O {
+	return p.outputs
+}
+
+// Cancel stops the Pool early, the remaining inputs are skipped and tasks should stop soon.
+func (p *Pool[I, O]) Cancel() {
+	p.cancel()
+}
+
+// Err returns the error of the first task that failed or panicked, once Outputs is closed.
+func (p *Pool[I, O]) Err() error {
+	return p.err
+}
+
+// workers returns the number of goroutines of the pool.
+func (c PoolConfig) workers() int {
+	if c.Workers <= 0 {
+		return runtime.NumCPU()
+	}
+	return c.Workers
+}
+
+// batch returns the number of removals in a task of the pool.
+func (c PoolConfig) batch() int {
+	if c.Batch <= 0 {
+		return defaultBatch
+	}
+	return c.Batch
 }
@@ -1,38 +1,200 @@
 package run
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"runtime"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rzamm/matchstick-solver/ec"
+	"github.com/rzamm/matchstick-solver/field"
 )
 
 func TestWorkers(t *testing.T) {
-	found := make(chan *taskReturn)
-
-	task := func(p *taskParams) {
+	task := func(ctx context.Context, p *taskParams, output func(*taskReturn) bool) error {
 		random := rand.Intn(5000) - 2500
 		time.Sleep(time.Duration(3000+random) * time.Millisecond)
 		fmt.Println(p.removeCombIndex)
 
 		if p.removeCombIndex == 200 {
-			found <- &taskReturn{}
+			output(&taskReturn{})
 		}
+		return nil
 	}
 
-	workers := Workers(task, found)
+	pool := NewPool(context.Background(), PoolConfig{}, func() Task[*taskParams, *taskReturn] { return task })
 
 	go func() {
-		for i := 0; i < 2000; i++ {
-			workers <- &taskParams{
-				removeCombIndex: i,
-			}
+		for i := 0; i < 2000 && pool.Send(&taskParams{removeCombIndex: i}); i++ {
 		}
-		close(workers)
+		pool.Close()
 	}()
 
-	for range found {
+	for range pool.Outputs() {
 		fmt.Println("Found!")
-		break
+		// the remaining inputs are skipped, so the outputs are closed soon after
+		pool.Cancel()
+	}
+	assert.NoError(t, pool.Err())
+}
+
+func TestPool(t *testing.T) {
+	// every worker has its own task, the outputs of all of them are collected
+	workers := int32(0)
+	pool := NewPool(context.Background(), PoolConfig{Workers: 3, Buffer: 2}, func() Task[int, int] {
+		atomic.AddInt32(&workers, 1)
+		return func(ctx context.Context, input int, output func(int) bool) error {
+			output(input * 2)
+			return nil
+		}
+	})
+	go func() {
+		for i := 0; i < 100; i++ {
+			pool.Send(i)
+		}
+		pool.Close()
+	}()
+	sum := 0
+	for output := range pool.Outputs() {
+		sum += output
+	}
+	assert.Equal(t, int32(3), workers)
+	assert.Equal(t, 9900, sum)
+	assert.NoError(t, pool.Err())
+
+	// the first error and a panic cancel the pool
+	failure := errors.New("failure")
+	for _, fail := range []func(){func() { panic("panic") }, nil} {
+		pool := NewPool(context.Background(), PoolConfig{}, func() Task[int, int] {
+			return func(ctx context.Context, input int, output func(int) bool) error {
+				if input == 10 {
+					if fail != nil {
+						fail()
+					}
+					return failure
+				}
+				output(input)
+				return nil
+			}
+		})
+		go func() {
+			for i := 0; pool.Send(i); i++ {
+			}
+			pool.Close()
+		}()
+		for range pool.Outputs() {
+		}
+		assert.Error(t, pool.Err())
+		if fail == nil {
+			assert.Equal(t, failure, pool.Err())
+		}
+	}
+
+	// a panic while creating a task fails the pool too
+	pool = NewPool(context.Background(), PoolConfig{Workers: 2}, func() Task[int, int] {
+		panic("no task")
+	})
+	go func() {
+		for i := 0; pool.Send(i); i++ {
+		}
+		pool.Close()
+	}()
+	for range pool.Outputs() {
+	}
+	assert.Error(t, pool.Err())
+
+	// cancelling the context cancels the pool
+	ctx, cancel := context.WithCancel(context.Background())
+	pool = NewPool(ctx, PoolConfig{}, func() Task[int, int] {
+		return func(ctx context.Context, input int, output func(int) bool) error {
+			if input == 10 {
+				cancel()
+			}
+			return nil
+		}
+	})
+	sent := 0
+	for pool.Send(sent) {
+		sent++
+	}
+	pool.Close()
+	for range pool.Outputs() {
+	}
+	assert.GreaterOrEqual(t, sent, 10)
+	assert.NoError(t, pool.Err())
+}
+
+func TestMoveGameContext(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	solutions, err := NewRun(Lvl16Test(true)).MoveGameContext(context.Background(), Options{MaxSolutions: 1})
+	assert.NoError(t, err)
+	assert.Len(t, solutions, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	solutions, err = NewRun(Lvl16Test(true)).MoveGameContext(ctx, Options{})
+	assert.Equal(t, context.Canceled, err)
+	assert.Empty(t, solutions)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	runner := NewRun(Lvl16Test(false))
+	_, err = runner.SolveGameContext(ctx, Options{})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	// the field is back in its initial state
+	assert.Len(t, runner.SolveGame(Options{MaxSolutions: 1}), 1)
+
+	// every goroutine has stopped, the one closing found may still be on its way out
+	for i := 0; i < 100 && runtime.NumGoroutine() > goroutines; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, goroutines, runtime.NumGoroutine())
+}
+
+func TestRemoveGameContext(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	solutions, err := NewRun(Lvl6(true)).RemoveGameContext(context.Background(), Options{MaxSolutions: 1})
+	assert.NoError(t, err)
+	assert.Len(t, solutions, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	solutions, err = NewRun(Lvl6(false)).RemoveGameContext(ctx, Options{})
+	assert.Equal(t, context.Canceled, err)
+	assert.Empty(t, solutions)
+
+	// every goroutine has stopped, the one closing found may still be on its way out
+	for i := 0; i < 100 && runtime.NumGoroutine() > goroutines; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, goroutines, runtime.NumGoroutine())
+}
+
+func TestRemoveGame(t *testing.T) {
+	for _, bit := range []bool{false, true} {
+		runner := NewRun(Lvl6(bit))
+
+		// try every removal one after the other
+		expected := make([]FieldI, 0)
+		removeComb := runner.removeCombAt(0)
+		for i := 0; i < runner.removeCombsTotal; i++ {
+			runner.field.ChangeToState(removeComb, field.Match, field.Space)
+			if runner.isSolution(runner.field) {
+				expected = append(expected, runner.field.Copy(true).(FieldI))
+			}
+			runner.field.ChangeToState(removeComb, field.Match, field.Match)
+			ec.NextCombination(removeComb, runner.matchCount, runner.movable)
+		}
+
+		assert.Greater(t, runner.removeCombsTotal, defaultBatch)
+		assert.ElementsMatch(t, layouts(expected), layouts(runner.RemoveGame(Options{})))
 	}
 }
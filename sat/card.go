@@ -0,0 +1,59 @@
+package sat
+
+// AtMost adds clauses so that at most k of the literals are true.
+// It uses a sequential counter, where counter i, j is true if at least j+1 of the first i+1 literals are true.
+func (s *Solver) AtMost(lits []Lit, k int) {
+	n := len(lits)
+	if k >= n {
+		return
+	}
+	if k <= 0 {
+		for _, l := range lits {
+			s.AddClause(-l)
+		}
+		return
+	}
+
+	counter := make([][]Lit, n-1)
+	for i := range counter {
+		counter[i] = make([]Lit, k)
+		for j := range counter[i] {
+			counter[i][j] = s.NewVar()
+		}
+	}
+
+	s.AddClause(-lits[0], counter[0][0])
+	for j := 1; j < k; j++ {
+		s.AddClause(-counter[0][j])
+	}
+	for i := 1; i < n-1; i++ {
+		s.AddClause(-lits[i], counter[i][0])
+		s.AddClause(-counter[i-1][0], counter[i][0])
+		for j := 1; j < k; j++ {
+			s.AddClause(-lits[i], -counter[i-1][j-1], counter[i][j])
+			s.AddClause(-counter[i-1][j], counter[i][j])
+		}
+		s.AddClause(-lits[i], -counter[i-1][k-1])
+	}
+	s.AddClause(-lits[n-1], -counter[n-2][k-1])
+}
+
+// AtLeast adds clauses so that at least k of the literals are true.
+func (s *Solver) AtLeast(lits []Lit, k int) {
+	negated := make([]Lit, len(lits))
+	for i, l := range lits {
+		negated[i] = -l
+	}
+	if k > len(lits) {
+		// cannot be satisfied
+		s.AddClause()
+		return
+	}
+	s.AtMost(negated, len(lits)-k)
+}
+
+// Exactly adds clauses so that exactly k of the literals are true.
+func (s *Solver) Exactly(lits []Lit, k int) {
+	s.AtMost(lits, k)
+	s.AtLeast(lits, k)
+}
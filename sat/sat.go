@@ -0,0 +1,361 @@
+// Package sat is a small CDCL (Conflict Driven Clause Learning) boolean satisfiability solver.
+package sat
+
+type (
+	// Lit is a literal, a variable or its negation.
+	// Variables are numbered from 1, and -v is the negation of variable v.
+	Lit int
+
+	// clause is a disjunction of internal literals,
+	// the first two literals are the ones being watched.
+	clause struct {
+		lits []int
+	}
+
+	// Solver finds assignments of variables that satisfy every clause added to it.
+	// Internally a literal of variable v is 2v if positive and 2v+1 if negative.
+	Solver struct {
+		vars     int
+		ok       bool // false once the clauses are known to be unsatisfiable
+		watches  [][]*clause
+		assigns  []int8 // 1 true, -1 false, 0 unassigned, per variable
+		level    []int
+		reason   []*clause
+		phase    []bool // the last value of each variable, used when branching on it again
+		activity []float64
+		varInc   float64
+		seen     []bool
+		trail    []int
+		trailLim []int
+		qhead    int
+	}
+)
+
+const (
+	varDecay     = 0.95
+	restartFirst = 100
+)
+
+// NewSolver returns a new Solver without variables or clauses.
+func NewSolver() *Solver {
+	s := &Solver{
+		ok:     true,
+		varInc: 1,
+	}
+	// variable 0 is unused
+	s.NewVar()
+	return s
+}
+
+// NewVar adds a new variable and returns its positive literal.
+func (s *Solver) NewVar() Lit {
+	v := s.vars
+	s.vars++
+	s.watches = append(s.watches, nil, nil)
+	s.assigns = append(s.assigns, 0)
+	s.level = append(s.level, 0)
+	s.reason = append(s.reason, nil)
+	s.phase = append(s.phase, false)
+	s.activity = append(s.activity, 0)
+	s.seen = append(s.seen, false)
+	return Lit(v)
+}
+
+// AddClause adds a clause that is satisfied when at least one of the literals is true.
+// Clauses can also be added after solving, to solve again with the new clause.
+func (s *Solver) AddClause(lits ...Lit) {
+	s.cancelUntil(0)
+	if !s.ok {
+		return
+	}
+
+	added := make(map[int]interface{}, len(lits))
+	c := &clause{lits: make([]int, 0, len(lits))}
+	for _, l := range lits {
+		p := s.code(l)
+		if _, ok := added[p^1]; ok || s.value(p) == 1 {
+			// always satisfied
+			return
+		}
+		if _, ok := added[p]; ok || s.value(p) == -1 {
+			continue
+		}
+		added[p] = nil
+		c.lits = append(c.lits, p)
+	}
+
+	switch len(c.lits) {
+	case 0:
+		s.ok = false
+	case 1:
+		s.enqueue(c.lits[0], nil)
+		s.ok = s.propagate() == nil
+	default:
+		s.attach(c)
+	}
+}
+
+// Solve returns true if there is an assignment that satisfies every clause.
+// The assignment can then be read with Value.
+func (s *Solver) Solve() bool {
+	s.cancelUntil(0)
+	if !s.ok {
+		return false
+	}
+
+	conflicts := 0
+	restarts := 0
+	nextRestart := restartFirst * luby(restarts)
+	for {
+		confl := s.propagate()
+		if confl != nil {
+			if len(s.trailLim) == 0 {
+				s.ok = false
+				return false
+			}
+			learnt, backtrackLevel := s.analyze(confl)
+			s.cancelUntil(backtrackLevel)
+			if len(learnt) == 1 {
+				s.enqueue(learnt[0], nil)
+			} else {
+				c := &clause{lits: learnt}
+				s.attach(c)
+				s.enqueue(learnt[0], c)
+			}
+			s.varInc /= varDecay
+			conflicts++
+			continue
+		}
+
+		if conflicts >= nextRestart {
+			restarts++
+			nextRestart = conflicts + restartFirst*luby(restarts)
+			s.cancelUntil(0)
+		}
+
+		v := s.pickBranch()
+		if v == 0 {
+			return true
+		}
+		s.trailLim = append(s.trailLim, len(s.trail))
+		if s.phase[v] {
+			s.enqueue(2*v, nil)
+		} else {
+			s.enqueue(2*v+1, nil)
+		}
+	}
+}
+
+// Value returns the value of a literal in the assignment found by the last call to Solve.
+func (s *Solver) Value(l Lit) bool {
+	return s.value(s.code(l)) == 1
+}
+
+// Models calls fn for every assignment of vars that is part of an assignment satisfying every clause,
+// until fn returns false. The assignment can be read with Value while fn runs.
+// Each assignment found is excluded by adding a clause, so the Solver cannot be reused for other problems.
+func (s *Solver) Models(vars []Lit, fn func() bool) {
+	for s.Solve() {
+		if !fn() || len(vars) == 0 {
+			return
+		}
+		block := make([]Lit, len(vars))
+		for i, v := range vars {
+			if s.Value(v) {
+				block[i] = -v
+			} else {
+				block[i] = v
+			}
+		}
+		s.AddClause(block...)
+	}
+}
+
+func (s *Solver) code(l Lit) int {
+	if l == 0 || int(l) >= s.vars || int(-l) >= s.vars {
+		panic("unknown variable")
+	}
+	if l < 0 {
+		return 2*int(-l) + 1
+	}
+	return 2 * int(l)
+}
+
+// value returns 1 if the literal p is true, -1 if false and 0 if unassigned.
+func (s *Solver) value(p int) int8 {
+	if p&1 == 1 {
+		return -s.assigns[p>>1]
+	}
+	return s.assigns[p>>1]
+}
+
+func (s *Solver) attach(c *clause) {
+	s.watches[c.lits[0]] = append(s.watches[c.lits[0]], c)
+	s.watches[c.lits[1]] = append(s.watches[c.lits[1]], c)
+}
+
+// enqueue makes p true, the reason is the clause that implied it or nil for a decision.
+func (s *Solver) enqueue(p int, reason *clause) {
+	v := p >> 1
+	if p&1 == 1 {
+		s.assigns[v] = -1
+	} else {
+		s.assigns[v] = 1
+	}
+	s.level[v] = len(s.trailLim)
+	s.reason[v] = reason
+	s.trail = append(s.trail, p)
+}
+
+func (s *Solver) cancelUntil(level int) {
+	if len(s.trailLim) <= level {
+		return
+	}
+	for i := len(s.trail) - 1; i >= s.trailLim[level]; i-- {
+		v := s.trail[i] >> 1
+		s.phase[v] = s.assigns[v] == 1
+		s.assigns[v] = 0
+		s.reason[v] = nil
+	}
+	s.trail = s.trail[:s.trailLim[level]]
+	s.trailLim = s.trailLim[:level]
+	s.qhead = len(s.trail)
+}
+
+// propagate assigns every literal implied by a clause, it returns a clause that became false or nil.
+// A clause implying a literal has that literal first.
+func (s *Solver) propagate() *clause {
+	for s.qhead < len(s.trail) {
+		falseLit := s.trail[s.qhead] ^ 1
+		s.qhead++
+
+		ws := s.watches[falseLit]
+		kept := ws[:0]
+		for i := 0; i < len(ws); i++ {
+			c := ws[i]
+			if c.lits[0] == falseLit {
+				c.lits[0], c.lits[1] = c.lits[1], c.lits[0]
+			}
+			if s.value(c.lits[0]) == 1 {
+				kept = append(kept, c)
+				continue
+			}
+
+			// look for a new literal to watch
+			moved := false
+			for k := 2; k < len(c.lits); k++ {
+				if s.value(c.lits[k]) != -1 {
+					c.lits[1], c.lits[k] = c.lits[k], c.lits[1]
+					s.watches[c.lits[1]] = append(s.watches[c.lits[1]], c)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+
+			kept = append(kept, c)
+			if s.value(c.lits[0]) == -1 {
+				kept = append(kept, ws[i+1:]...)
+				s.watches[falseLit] = kept
+				s.qhead = len(s.trail)
+				return c
+			}
+			s.enqueue(c.lits[0], c)
+		}
+		s.watches[falseLit] = kept
+	}
+	return nil
+}
+
+// analyze returns a learnt clause from a conflict and the level to backtrack to.
+// The learnt clause has a single literal from the current level (the first unique implication point),
+// placed first so that it is implied after backtracking.
+func (s *Solver) analyze(confl *clause) ([]int, int) {
+	learnt := []int{0}
+	current := len(s.trailLim)
+	pathCount := 0
+	p := -1
+	index := len(s.trail) - 1
+
+	for {
+		start := 0
+		if p >= 0 {
+			// the first literal of a reason is the one it implied
+			start = 1
+		}
+		for _, q := range confl.lits[start:] {
+			v := q >> 1
+			if s.seen[v] || s.level[v] == 0 {
+				continue
+			}
+			s.bump(v)
+			s.seen[v] = true
+			if s.level[v] == current {
+				pathCount++
+			} else {
+				learnt = append(learnt, q)
+			}
+		}
+
+		for !s.seen[s.trail[index]>>1] {
+			index--
+		}
+		p = s.trail[index]
+		index--
+		confl = s.reason[p>>1]
+		s.seen[p>>1] = false
+		pathCount--
+		if pathCount == 0 {
+			break
+		}
+	}
+	learnt[0] = p ^ 1
+
+	backtrackLevel := 0
+	for i := 1; i < len(learnt); i++ {
+		s.seen[learnt[i]>>1] = false
+		if l := s.level[learnt[i]>>1]; l > backtrackLevel {
+			backtrackLevel = l
+			learnt[1], learnt[i] = learnt[i], learnt[1]
+		}
+	}
+	return learnt, backtrackLevel
+}
+
+func (s *Solver) bump(v int) {
+	s.activity[v] += s.varInc
+	if s.activity[v] > 1e100 {
+		for i := range s.activity {
+			s.activity[i] *= 1e-100
+		}
+		s.varInc *= 1e-100
+	}
+}
+
+// pickBranch returns the unassigned variable with the highest activity, or 0 if all are assigned.
+func (s *Solver) pickBranch() int {
+	best := 0
+	for v := 1; v < s.vars; v++ {
+		if s.assigns[v] == 0 && (best == 0 || s.activity[v] > s.activity[best]) {
+			best = v
+		}
+	}
+	return best
+}
+
+// luby returns the i-th number of the Luby sequence 1 1 2 1 1 2 4 1 1 2 ..., used to space out restarts.
+func luby(i int) int {
+	size, power := 1, 1
+	for size < i+1 {
+		size = 2*size + 1
+		power *= 2
+	}
+	for size-1 != i {
+		size = (size - 1) / 2
+		power /= 2
+		i %= size
+	}
+	return power
+}
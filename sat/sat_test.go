@@ -0,0 +1,120 @@
+package sat
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/stat/combin"
+)
+
+func newVars(s *Solver, n int) []Lit {
+	vars := make([]Lit, n)
+	for i := range vars {
+		vars[i] = s.NewVar()
+	}
+	return vars
+}
+
+func TestPigeonhole(t *testing.T) {
+	// 5 pigeons cannot fit in 4 holes
+	pigeons, holes := 5, 4
+	s := NewSolver()
+	in := make([][]Lit, pigeons)
+	for p := range in {
+		in[p] = newVars(s, holes)
+		s.AddClause(in[p]...)
+	}
+	for h := 0; h < holes; h++ {
+		for p := 0; p < pigeons; p++ {
+			for q := p + 1; q < pigeons; q++ {
+				s.AddClause(-in[p][h], -in[q][h])
+			}
+		}
+	}
+	assert.False(t, s.Solve())
+}
+
+func TestCardinality(t *testing.T) {
+	n := 8
+	for k := 0; k <= n; k++ {
+		s := NewSolver()
+		vars := newVars(s, n)
+		s.Exactly(vars, k)
+
+		models := 0
+		s.Models(vars, func() bool {
+			count := 0
+			for _, v := range vars {
+				if s.Value(v) {
+					count++
+				}
+			}
+			assert.Equal(t, k, count)
+			models++
+			return true
+		})
+		assert.Equal(t, combin.Binomial(n, k), models)
+	}
+
+	s := NewSolver()
+	s.AtLeast(newVars(s, 3), 4)
+	assert.False(t, s.Solve())
+}
+
+func TestRandom3SAT(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	n := 12
+	for round := 0; round < 50; round++ {
+		s := NewSolver()
+		vars := newVars(s, n)
+		clauses := make([][]Lit, 0)
+		for c := 0; c < 50; c++ {
+			cl := make([]Lit, 3)
+			for i := range cl {
+				cl[i] = vars[rnd.Intn(n)]
+				if rnd.Intn(2) == 0 {
+					cl[i] = -cl[i]
+				}
+			}
+			clauses = append(clauses, cl)
+			s.AddClause(cl...)
+		}
+
+		// count the models by trying every assignment
+		expected := 0
+		for a := 0; a < 1<<n; a++ {
+			satisfied := true
+			for _, cl := range clauses {
+				ok := false
+				for _, l := range cl {
+					v := int(l)
+					if v < 0 {
+						v = -v
+					}
+					if (a>>(v-1)&1 == 1) == (l > 0) {
+						ok = true
+						break
+					}
+				}
+				if !ok {
+					satisfied = false
+					break
+				}
+			}
+			if satisfied {
+				expected++
+			}
+		}
+
+		models := 0
+		s.Models(vars, func() bool {
+			for _, cl := range clauses {
+				assert.True(t, s.Value(cl[0]) || s.Value(cl[1]) || s.Value(cl[2]))
+			}
+			models++
+			return true
+		})
+		assert.Equal(t, expected, models)
+	}
+}